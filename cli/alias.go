@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxAliasDepth bounds how many aliases can point at each other before a
+// version is reached, which also stops alias cycles.
+const maxAliasDepth = 8
+
+// reservedAliases can't be used as alias names because they clash with
+// commands or special version names.
+var reservedAliases = []string{"ls", "list", "-", "latest"}
+
+// ResolveAlias returns the version name points to, following aliases that
+// point at other aliases. Names that aren't aliases are returned as is,
+// except that master@<sha> is shortened to the name its snapshot is
+// installed under.
+func (o *OVM) ResolveAlias(name string) string {
+	for i := 0; i < maxAliasDepth; i++ {
+		target, ok := o.Config.Aliases[name]
+		if !ok {
+			break
+		}
+		name = target
+	}
+
+	if sha, ok := strings.CutPrefix(name, "master@"); ok {
+		name = "master@" + shortSHA(sha)
+	}
+
+	return name
+}
+
+// resolveSelection replaces an alias in sel with the version it points to.
+func (o *OVM) resolveSelection(sel VersionSelection) VersionSelection {
+	if version := o.ResolveAlias(sel.Version); version != sel.Version {
+		sel.Alias = sel.Version
+		sel.Version = version
+	}
+
+	return sel
+}
+
+func (o *OVM) SetAlias(name, version string) error {
+	if name == "" || strings.ContainsAny(name, `/\@`) {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+
+	for _, r := range reservedAliases {
+		if name == r {
+			return fmt.Errorf("%q is reserved and can't be used as an alias", name)
+		}
+	}
+
+	// an alias shadowing a version would make commands like `ovm rm` act on
+	// the wrong install
+	if name == "master" || strings.HasPrefix(name, "dev-") || o.IsInstalled(name) {
+		return fmt.Errorf("%q is a version name and can't be used as an alias", name)
+	}
+
+	if o.ResolveAlias(version) == name {
+		return fmt.Errorf("alias %s can't point to itself", name)
+	}
+
+	if err := validateVersion(o.ResolveAlias(version)); err != nil {
+		return err
+	}
+
+	if err := o.Config.SetAlias(name, version); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s -> %s\n", o.Colored(name, "green"), version)
+	if resolved := o.ResolveAlias(version); !o.IsInstalled(resolved) {
+		fmt.Printf("%s is not installed yet. Run `ovm install %s` to install it.\n", resolved, resolved)
+	}
+
+	return nil
+}
+
+func (o *OVM) RemoveAlias(name string) error {
+	if _, ok := o.Config.Aliases[name]; !ok {
+		return fmt.Errorf("%s is not an alias", name)
+	}
+
+	if err := o.Config.RemoveAlias(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("✔ Removed alias %s.\n", name)
+	return nil
+}
+
+func (o *OVM) ListAliases() error {
+	if len(o.Config.Aliases) == 0 {
+		fmt.Println("No aliases defined. Add one with `ovm alias <name> <version>`.")
+		return nil
+	}
+
+	names := make([]string, 0, len(o.Config.Aliases))
+	for name := range o.Config.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Version aliases:")
+	for _, name := range names {
+		fmt.Printf("%s -> %s\n", o.Colored(name, "green"), o.Config.Aliases[name])
+	}
+
+	return nil
+}
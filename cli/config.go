@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/pelletier/go-toml/v2"
@@ -10,10 +11,74 @@ import (
 )
 
 type Config struct {
-	basePath          string
+	basePath string
+	// SchemaVersion is the layout of config.toml, see configMigrations
+	SchemaVersion     int `toml:"schema_version"`
 	UseColor          bool
 	ActiveVersion     string
 	InstalledVersions []string
+	// BuildMode is passed to build_odin.sh, empty uses the script's default
+	BuildMode string
+	Installs  map[string]InstallInfo
+	Aliases   map[string]string
+	// MasterSnapshots keeps every master build as master@<sha> instead of
+	// overwriting ~/.ovm/master
+	MasterSnapshots bool
+	// MasterRetention is how many snapshots are kept, 0 means the default of 3
+	MasterRetention int
+	// UpdateNotice enables a daily notice when a newer release is available
+	UpdateNotice    bool
+	LastUpdateCheck time.Time
+	// History lists recent version switches, newest first
+	History []HistoryEntry
+	OLS     OLSConfig
+}
+
+// InstallInfo records how an installed version was made.
+type InstallInfo struct {
+	SourceURL string
+	Commit    string
+	// Tracks is the moving ref ("master" or "latest") this install follows
+	Tracks      string
+	BuildMode   string
+	PublishedAt time.Time
+	InstalledAt time.Time
+}
+
+type HistoryEntry struct {
+	Version    string
+	SwitchedAt time.Time
+}
+
+// historyLimit is how many version switches are remembered.
+const historyLimit = 20
+
+type OLSConfig struct {
+	// Active is the directory name of the OLS build linked into ~/.ovm/bin
+	Active string
+	// AutoRebuild keeps OLS in step with the active Odin version on `ovm use`
+	AutoRebuild bool
+	Builds      []OLSBuild
+	Formatter   FormatterConfig
+}
+
+type OLSBuild struct {
+	Ref         string
+	Commit      string
+	OdinVersion string
+	BuiltAt     time.Time
+}
+
+// Dir returns the name of the directory under ~/.ovm/ols holding this build.
+func (b OLSBuild) Dir() string {
+	return fmt.Sprintf("%s_%s", b.OdinVersion, shortSHA(b.Commit))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
 }
 
 func (c *Config) save() error {
@@ -56,7 +121,7 @@ func (c *Config) EnableColor() {
 func (c *Config) AddInstalledVersion(version string) error {
 	for _, v := range c.InstalledVersions {
 		if v == version {
-			return nil
+			return c.save()
 		}
 	}
 
@@ -65,11 +130,101 @@ func (c *Config) AddInstalledVersion(version string) error {
 }
 
 func (c *Config) RemoveInstalledVersion(version string) error {
+	delete(c.Installs, version)
 	for i, v := range c.InstalledVersions {
 		if v == version {
-			c.InstalledVersions[i] = c.InstalledVersions[len(c.InstalledVersions)-1]
-			c.InstalledVersions = c.InstalledVersions[:len(c.InstalledVersions)-1]
+			c.InstalledVersions = append(c.InstalledVersions[:i], c.InstalledVersions[i+1:]...)
+			break
 		}
 	}
 	return c.save()
 }
+
+// SetInstallInfo records info for version. It is saved together with the
+// next change to the installed versions.
+func (c *Config) SetInstallInfo(version string, info InstallInfo) {
+	if c.Installs == nil {
+		c.Installs = make(map[string]InstallInfo)
+	}
+
+	c.Installs[version] = info
+}
+
+// buildMode returns the build_odin.sh mode new installs are built with.
+func (c *Config) buildMode() string {
+	if c.BuildMode == "" {
+		return "debug"
+	}
+	return c.BuildMode
+}
+
+// SetActiveVersion makes version the active version and records the switch
+// in the history.
+func (c *Config) SetActiveVersion(version string) error {
+	if version != c.ActiveVersion {
+		// configs from before the history was kept only know the active version
+		if len(c.History) == 0 && c.ActiveVersion != "" {
+			c.History = []HistoryEntry{{Version: c.ActiveVersion}}
+		}
+
+		c.History = append([]HistoryEntry{{Version: version, SwitchedAt: time.Now()}}, c.History...)
+		if len(c.History) > historyLimit {
+			c.History = c.History[:historyLimit]
+		}
+	}
+
+	c.ActiveVersion = version
+	return c.save()
+}
+
+// PreviousVersion returns the most recent version in the history other than
+// the active one.
+func (c *Config) PreviousVersion() (string, bool) {
+	for _, h := range c.History {
+		if h.Version != c.ActiveVersion {
+			return h.Version, true
+		}
+	}
+
+	return "", false
+}
+
+func (c *Config) SetAlias(name, version string) error {
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+
+	c.Aliases[name] = version
+	return c.save()
+}
+
+func (c *Config) RemoveAlias(name string) error {
+	delete(c.Aliases, name)
+	return c.save()
+}
+
+// SetOLSBuild records build and makes it the active OLS build.
+func (c *Config) SetOLSBuild(build OLSBuild) error {
+	for i, b := range c.OLS.Builds {
+		if b.Dir() == build.Dir() {
+			c.OLS.Builds[i] = build
+			c.OLS.Active = build.Dir()
+			return c.save()
+		}
+	}
+
+	c.OLS.Builds = append(c.OLS.Builds, build)
+	c.OLS.Active = build.Dir()
+	return c.save()
+}
+
+// ActiveOLSBuild returns the OLS build currently linked into ~/.ovm/bin.
+func (c *Config) ActiveOLSBuild() (OLSBuild, bool) {
+	for _, b := range c.OLS.Builds {
+		if b.Dir() == c.OLS.Active {
+			return b, true
+		}
+	}
+
+	return OLSBuild{}, false
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"ovm/cli/meta"
+	"path/filepath"
+	"runtime"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// fetchSource downloads the zip archive at url, extracts it into the ovm
+// directory and returns the path of the extracted top-level directory and the
+// SHA-256 of the archive. Archives with a non-empty cacheKey are kept in
+// the cache directory and reused instead of being downloaded again.
+func (o *OVM) fetchSource(url, cacheKey, label string) (string, string, error) {
+	var archive string
+	if cacheKey != "" {
+		archive = filepath.Join(o.cacheDir, cacheKey+".zip")
+	}
+
+	if _, err := os.Stat(archive); archive == "" || err != nil {
+		downloaded, err := o.download(url, label)
+		if err != nil {
+			return "", "", err
+		}
+
+		if archive == "" {
+			defer os.Remove(downloaded)
+			archive = downloaded
+		} else {
+			if err := os.MkdirAll(filepath.Dir(archive), 0775); err != nil {
+				os.Remove(downloaded)
+				return "", "", err
+			}
+
+			if err := os.Rename(downloaded, archive); err != nil {
+				os.Remove(downloaded)
+				return "", "", err
+			}
+		}
+	} else if o.Verbose {
+		fmt.Printf("Using cached archive `%s`\n", archive)
+	}
+
+	archiveSum, err := fileSHA256(archive)
+	if err != nil {
+		return "", "", err
+	}
+
+	fmt.Println("\nExtracting...")
+
+	extractedDir, err := o.unzipSource(archive)
+	if err != nil {
+		// a cached archive that can't be extracted would fail the same way
+		// on every retry
+		if cacheKey != "" {
+			os.Remove(archive)
+		}
+		return "", "", err
+	}
+
+	return filepath.Join(o.baseDir, extractedDir), archiveSum, nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// download fetches url into a temporary file inside the ovm directory and
+// returns its path. The caller is responsible for removing it.
+func (o *OVM) download(url, label string) (string, error) {
+	downloadReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	downloadReq.Header.Set("User-Agent", "ovm "+meta.VERSION)
+	downloadReq.Header.Set("X-Client-Os", runtime.GOOS)
+	downloadReq.Header.Set("X-Client-Arch", runtime.GOARCH)
+
+	downloadRes, err := http.DefaultClient.Do(downloadReq)
+	if err != nil {
+		return "", err
+	}
+	defer downloadRes.Body.Close()
+
+	if downloadRes.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", url, downloadRes.Status)
+	}
+
+	tempFile, err := os.CreateTemp(o.baseDir, "*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer tempFile.Close()
+
+	pbar := progressbar.DefaultBytes(
+		int64(downloadRes.ContentLength),
+		fmt.Sprintf("Downloading %s: ", label),
+	)
+
+	if _, err = io.Copy(io.MultiWriter(tempFile, pbar), downloadRes.Body); err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+
+	return tempFile.Name(), nil
+}
+
+// moveInto replaces dest with the directory at source.
+func (o *OVM) moveInto(source, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		if o.Verbose {
+			fmt.Printf("Destination directory `%s` exists. Removing it.\n", dest)
+		}
+
+		if err = os.RemoveAll(dest); err != nil {
+			return fmt.Errorf("failed to remove existing directory %s: %w", dest, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0775); err != nil {
+		return err
+	}
+
+	if err := os.Rename(source, dest); err != nil {
+		return err
+	}
+
+	if o.Verbose {
+		fmt.Printf("Moved extracted directory `%s` to `%s`\n", source, dest)
+	}
+
+	return nil
+}
+
+// writeBuildLog stores the output of a build in ~/.ovm/logs and returns the
+// path of the log file.
+func (o *OVM) writeBuildLog(name string, output []byte) (string, error) {
+	logDir := filepath.Join(o.baseDir, "logs")
+	if err := os.MkdirAll(logDir, 0775); err != nil {
+		return "", err
+	}
+
+	logPath := filepath.Join(logDir, name+".log")
+	if err := os.WriteFile(logPath, output, 0644); err != nil {
+		return "", err
+	}
+
+	return logPath, nil
+}
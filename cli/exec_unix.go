@@ -0,0 +1,16 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+const exeSuffix = ""
+
+// execBinary replaces the ovm process with path, so shims add no overhead
+// once the binary is running.
+func execBinary(path string, args []string) error {
+	return syscall.Exec(path, append([]string{path}, args...), os.Environ())
+}
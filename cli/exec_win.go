@@ -0,0 +1,22 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+)
+
+const exeSuffix = ".exe"
+
+// execBinary runs path as a child process and exits with its exit code,
+// since Windows has no exec.
+func execBinary(path string, args []string) error {
+	code, err := runPassthrough(exec.Command(path, args...))
+	if err != nil {
+		return err
+	}
+
+	os.Exit(code)
+	return nil
+}
@@ -0,0 +1,305 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// homeLayout is where ovm keeps its files. Base holds the installs, bin,
+// collections, OLS builds and logs; by default the cache and config.toml
+// live inside it too.
+type homeLayout struct {
+	Base   string
+	Cache  string
+	Config string
+}
+
+func homeLayoutAt(base string) homeLayout {
+	return homeLayout{
+		Base:   base,
+		Cache:  filepath.Join(base, "cache"),
+		Config: filepath.Join(base, "config.toml"),
+	}
+}
+
+// findHome picks the ovm home: OVM_HOME when it is set, the XDG base
+// directories when they are opted into, or ~/.ovm.
+func findHome() (homeLayout, error) {
+	if dir := os.Getenv("OVM_HOME"); dir != "" {
+		base, err := filepath.Abs(dir)
+		if err != nil {
+			return homeLayout{}, err
+		}
+		return homeLayoutAt(base), nil
+	}
+
+	// a missing home directory only matters for paths that fall back to it
+	home, homeErr := os.UserHomeDir()
+	if homeErr != nil {
+		homeErr = fmt.Errorf("can't find your home directory, set OVM_HOME to choose where ovm keeps its files: %w", homeErr)
+	}
+
+	if useXDG(home) {
+		layout := homeLayout{}
+		for _, dir := range []struct {
+			path     *string
+			env      string
+			fallback string
+		}{
+			{&layout.Base, "XDG_DATA_HOME", ".local/share"},
+			{&layout.Cache, "XDG_CACHE_HOME", ".cache"},
+			{&layout.Config, "XDG_CONFIG_HOME", ".config"},
+		} {
+			base := os.Getenv(dir.env)
+			if !filepath.IsAbs(base) {
+				if homeErr != nil {
+					return homeLayout{}, homeErr
+				}
+				base = filepath.Join(home, dir.fallback)
+			}
+			*dir.path = filepath.Join(base, "ovm")
+		}
+		layout.Config = filepath.Join(layout.Config, "config.toml")
+
+		return layout, nil
+	}
+
+	if homeErr != nil {
+		return homeLayout{}, homeErr
+	}
+
+	return homeLayoutAt(filepath.Join(home, ".ovm")), nil
+}
+
+// useXDG reports whether the XDG layout is used: when OVM_XDG is set to a
+// true value, or when it is unset and an XDG config file already exists.
+func useXDG(home string) bool {
+	if v, ok := os.LookupEnv("OVM_XDG"); ok {
+		enabled, _ := strconv.ParseBool(v)
+		return enabled
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		if home == "" {
+			return false
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	_, err := os.Stat(filepath.Join(configHome, "ovm", "config.toml"))
+	return err == nil
+}
+
+// Relocate moves the ovm home to newDir, bringing the cache and config.toml
+// along when they live elsewhere, and rewrites the absolute symlinks that
+// pointed into the old home.
+func (o *OVM) Relocate(newDir string) error {
+	newDir, err := filepath.Abs(newDir)
+	if err != nil {
+		return err
+	}
+
+	oldDir := o.baseDir
+	if newDir == oldDir {
+		return fmt.Errorf("ovm already lives in %s", oldDir)
+	}
+
+	if strings.HasPrefix(newDir, oldDir+string(os.PathSeparator)) {
+		return fmt.Errorf("can't move %s into itself", oldDir)
+	}
+
+	if entries, err := os.ReadDir(newDir); err == nil {
+		if len(entries) > 0 {
+			return fmt.Errorf("%s already exists and isn't empty", newDir)
+		}
+		if err := os.Remove(newDir); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newDir), 0775); err != nil {
+		return err
+	}
+
+	fmt.Printf("Moving %s to %s...\n", oldDir, o.Colored(newDir, "green"))
+	if err := o.moveHome(oldDir, newDir); err != nil {
+		return err
+	}
+
+	moved := homeLayoutAt(newDir)
+	if !isWithin(o.Config.basePath, oldDir) {
+		if err := moveDir(o.Config.basePath, moved.Config); err != nil {
+			return err
+		}
+	}
+
+	if !isWithin(o.cacheDir, oldDir) {
+		if _, err := os.Stat(o.cacheDir); err == nil {
+			if err := moveDir(o.cacheDir, moved.Cache); err != nil {
+				return err
+			}
+		}
+	}
+
+	o.baseDir = moved.Base
+	o.cacheDir = moved.Cache
+	o.Config.basePath = moved.Config
+
+	rewritten, err := rewriteSymlinks(newDir, oldDir)
+	if err != nil {
+		return err
+	}
+
+	if o.Verbose {
+		fmt.Printf("Updated %d links\n", rewritten)
+	}
+
+	// the global ols.json lists the collection paths
+	if _, err := os.Stat(filepath.Join(newDir, "ols", "ols.json")); err == nil {
+		if err := o.OLSConfigure(true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✔ Moved ovm to %s.\n", newDir)
+	fmt.Println("To finish, update your shell profile:")
+	fmt.Printf("  export OVM_HOME=\"%s\"\n", newDir)
+	fmt.Printf("  replace %s with %s on your PATH\n", filepath.Join(oldDir, "bin"), filepath.Join(newDir, "bin"))
+	if dir := os.Getenv("OVM_INSTALL"); isWithin(dir, oldDir) {
+		fmt.Printf("  export OVM_INSTALL=\"%s\"\n", filepath.Join(newDir, strings.TrimPrefix(dir, oldDir)))
+	}
+	fmt.Println("Project ols.json files written with `ovm ols config` still point to the old collections, run it again in each project.")
+
+	return nil
+}
+
+// moveHome moves everything in the home at oldDir to newDir. The lock is
+// kept until the rest has moved, so no other ovm process can write into the
+// old home meanwhile; the lock file itself is moved last, once it is
+// released, since Windows can't move open files.
+func (o *OVM) moveHome(oldDir, newDir string) error {
+	if err := os.MkdirAll(newDir, 0775); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(oldDir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.Name() == lockName {
+			continue
+		}
+
+		if err := moveDir(filepath.Join(oldDir, e.Name()), filepath.Join(newDir, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	o.Unlock()
+	if err := moveDir(filepath.Join(oldDir, lockName), filepath.Join(newDir, lockName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.Remove(oldDir); err != nil {
+		log.Warn("Failed to remove the old ovm directory", "path", oldDir, "err", err)
+	}
+
+	return nil
+}
+
+// isWithin reports whether path is dir or inside it.
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// moveDir renames the file or directory source to dest, copying it across
+// file systems when a rename isn't possible.
+func moveDir(source, dest string) error {
+	if err := os.Rename(source, dest); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0775); err != nil {
+		return err
+	}
+
+	err := filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.IsDir():
+			return os.MkdirAll(target, 0775)
+		default:
+			return copyFile(path, target)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", source, dest, err)
+	}
+
+	return os.RemoveAll(source)
+}
+
+// rewriteSymlinks points the absolute symlinks under root that lead into
+// oldDir at the same path under root instead, and returns how many it
+// changed.
+func rewriteSymlinks(root, oldDir string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+
+		if !filepath.IsAbs(target) || !isWithin(target, oldDir) {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+
+		if err := os.Symlink(filepath.Join(root, strings.TrimPrefix(target, oldDir)), path); err != nil {
+			return err
+		}
+
+		count++
+		return nil
+	})
+
+	return count, err
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// The hooks only call back into ovm when the working directory changes, so
+// a prompt in the same directory costs nothing.
+const bashHook = `_ovm_hook() {
+  if [ "$PWD" != "$_OVM_LAST_PWD" ]; then
+    _OVM_LAST_PWD="$PWD"
+    eval "$(ovm env --hook --shell bash%[1]s)"
+  fi
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_ovm_hook;"* ]]; then
+  PROMPT_COMMAND="_ovm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`
+
+const zshHook = `_ovm_hook() {
+  if [[ "$PWD" != "$_OVM_LAST_PWD" ]]; then
+    _OVM_LAST_PWD="$PWD"
+    eval "$(ovm env --hook --shell zsh%[1]s)"
+  fi
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd _ovm_hook
+add-zsh-hook precmd _ovm_hook
+`
+
+const fishHook = `function _ovm_hook --on-variable PWD
+    ovm env --hook --shell fish%[1]s | source
+end
+_ovm_hook
+`
+
+// Init prints the shell snippet that switches versions on directory change.
+func (o *OVM) Init(shell string, installMissing bool) error {
+	var hook string
+	switch shell {
+	case "bash":
+		hook = bashHook
+	case "zsh":
+		hook = zshHook
+	case "fish":
+		hook = fishHook
+	default:
+		return fmt.Errorf("unsupported shell %q, expected bash, zsh or fish", shell)
+	}
+
+	var extra string
+	if installMissing {
+		extra = " --install-missing"
+	}
+
+	fmt.Printf(hook, extra)
+	return nil
+}
+
+// HookEnv prints the commands run by the shell hook after a directory
+// change. Inside a project it selects the pinned version, and after leaving
+// one it undoes what the hook set. Versions chosen by hand with `ovm env` are
+// left alone.
+func (o *OVM) HookEnv(shell string, installMissing bool) error {
+	hookVersion := os.Getenv("_OVM_HOOK_VERSION")
+	if hookVersion == "" && os.Getenv("OVM_VERSION") != "" {
+		return nil
+	}
+
+	sel, err := findProjectVersion()
+	if err != nil {
+		if hookVersion != "" {
+			return o.printHookReset(shell)
+		}
+		return nil
+	}
+	sel = o.resolveSelection(sel)
+
+	if sel.Version == hookVersion {
+		return nil
+	}
+
+	installed := sel.Version
+	if !o.IsInstalled(sel.Version) {
+		if !installMissing {
+			fmt.Fprintf(os.Stderr, "ovm: %s (set by %s) is not installed. Run `ovm install` to install it.\n", sel.Version, sel.Path)
+			return nil
+		}
+
+		var err error
+		installed, err = o.ensureInstalled(sel.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	env := o.sessionEnv(installed)
+	env["_OVM_HOOK_VERSION"] = sel.Version
+	for _, k := range append(sessionVars, "_OVM_HOOK_VERSION") {
+		line, err := exportLine(shell, k, env[k])
+		if err != nil {
+			return err
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+func (o *OVM) printHookReset(shell string) error {
+	path, err := exportLine(shell, "PATH", o.stripVersionDirs(os.Getenv("PATH")))
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+
+	vars := []string{"ODIN_ROOT", "OVM_VERSION", "_OVM_HOOK_VERSION"}
+	if shell == "fish" {
+		fmt.Printf("set -e %s;\n", strings.Join(vars, " "))
+	} else {
+		fmt.Printf("unset %s;\n", strings.Join(vars, " "))
+	}
+
+	return nil
+}
@@ -5,191 +5,140 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
-	"ovm/cli/meta"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/schollz/progressbar/v3"
 )
 
 func (o *OVM) Install(version TargetVersion, installLsp bool) error {
-	downloadReq, err := http.NewRequest("GET", version.ZipUrl, nil)
-	if err != nil {
-		return err
-	}
-
-	downloadReq.Header.Set("User-Agent", "ovm "+meta.VERSION)
-	downloadReq.Header.Set("X-Client-Os", runtime.GOOS)
-	downloadReq.Header.Set("X-Client-Arch", runtime.GOARCH)
-
-	downloadRes, err := http.DefaultClient.Do(downloadReq)
-	if err != nil {
-		return err
-	}
-	defer downloadRes.Body.Close()
-
-	tempDir, err := os.CreateTemp(o.baseDir, "*.zip")
-	if err != nil {
-		return err
-	}
-	defer tempDir.Close()
-	defer os.RemoveAll(tempDir.Name())
-
-	versionStr := o.Colored(version.Tag, "green")
-	pbar := progressbar.DefaultBytes(
-		int64(downloadRes.ContentLength),
-		fmt.Sprintf("Downloading %s: ", versionStr),
-	)
-
-	_, err = io.Copy(io.MultiWriter(tempDir, pbar), downloadRes.Body)
-	if err != nil {
-		return err
-	}
-
-	fmt.Println("\nExtracting...")
-
-	extractedDir, err := o.unzipSource(tempDir.Name())
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	outPath := filepath.Join(o.baseDir, extractedDir)
-	newPath := filepath.Join(o.baseDir, version.Tag)
-
-	if _, err = os.Stat(newPath); err == nil {
-		if o.Verbose {
-			fmt.Printf("Destination directory `%s` exists. Removing it.\n", newPath)
-		}
-
-		if err = os.RemoveAll(newPath); err != nil {
-			log.Warn("Failed to remove existing directory", "dir", newPath)
-		}
-	}
-
-	if err := os.Rename(outPath, newPath); err != nil {
+	if err := o.buildVersion(version); err != nil {
 		log.Fatal(err)
 	}
 
-	if o.Verbose {
-		fmt.Printf("Moved extracted directory `%s` to `%s`\n", outPath, newPath)
-	}
+	o.installShims()
 
-	fmt.Printf("Building %s...\n", o.Colored("Odin", "cyan"))
-	if err := o.buildSource(newPath, "build_odin.sh", nil); err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(o.Colored("Build successful!\n", "green"))
-
-	odinBinary := filepath.Join(newPath, "odin")
-	o.createSymlink(odinBinary, "bin")
-
-	coreLib := filepath.Join(newPath, "core")
+	coreLib := filepath.Join(o.baseDir, version.Tag, "core")
 	o.createSymlink(coreLib, "collections")
 
 	o.linkCollections(version.Tag)
 
-	o.Config.ActiveVersion = version.Tag
-	if err := o.Config.save(); err != nil {
-		return err
-	}
-
-	if err := o.Config.AddInstalledVersion(version.Tag); err != nil {
+	if err := o.Config.SetActiveVersion(version.Tag); err != nil {
 		return err
 	}
 
 	if installLsp {
-		if err := o.installOLS(); err != nil {
+		if err := o.installOLS("master"); err != nil {
 			log.Fatal(err)
 		}
+	} else if err := o.syncOLS(); err != nil {
+		log.Fatal(err)
 	}
 
 	fmt.Println("Done! 🍻")
 	return nil
 }
 
-func (o *OVM) installOLS() error {
-	olsZipUrl := "https://github.com/DanielGavin/ols/archive/refs/heads/master.zip"
+// sourceCacheKey names the cached source archive of an Odin install. Master
+// builds are cached by commit, since every build of master shares its name.
+func sourceCacheKey(tag, commit string) string {
+	if tag != "master" && !strings.HasPrefix(tag, "master@") {
+		return tag
+	}
 
-	downloadReq, err := http.NewRequest("GET", olsZipUrl, nil)
-	if err != nil {
-		return err
+	if commit == "" {
+		return ""
 	}
 
-	downloadReq.Header.Set("User-Agent", "ovm "+meta.VERSION)
-	downloadReq.Header.Set("X-Client-Os", runtime.GOOS)
-	downloadReq.Header.Set("X-Client-Arch", runtime.GOARCH)
+	return "master-" + commit
+}
 
-	downloadRes, err := http.DefaultClient.Do(downloadReq)
-	if err != nil {
-		return err
-	}
-	defer downloadRes.Body.Close()
+// buildVersion downloads and builds version into ~/.ovm/<tag> and records it
+// as installed, without making it the active version.
+func (o *OVM) buildVersion(version TargetVersion) error {
+	started := time.Now()
+	versionStr := o.Colored(version.Tag, "green")
+	cacheKey := sourceCacheKey(version.Tag, version.Commit)
 
-	tempDir, err := os.CreateTemp(o.baseDir, "*.zip")
+	outPath, archiveSum, err := o.fetchSource(version.ZipUrl, cacheKey, versionStr)
 	if err != nil {
 		return err
 	}
-	defer tempDir.Close()
-	defer os.RemoveAll(tempDir.Name())
 
-	pbar := progressbar.DefaultBytes(
-		int64(downloadRes.ContentLength),
-		fmt.Sprintf("Downloading OLS"),
-	)
+	// a rebuilt master replaces the archive of the commit it was built from
+	if previous, ok := o.Config.Installs[version.Tag]; ok && previous.Commit != version.Commit {
+		if key := sourceCacheKey(version.Tag, previous.Commit); key != "" && key != version.Tag {
+			os.Remove(filepath.Join(o.cacheDir, key+".zip"))
+		}
+	}
 
-	_, err = io.Copy(io.MultiWriter(tempDir, pbar), downloadRes.Body)
-	if err != nil {
+	newPath := filepath.Join(o.baseDir, version.Tag)
+	if err := o.moveInto(outPath, newPath); err != nil {
 		return err
 	}
 
-	fmt.Println("\nExtracting...")
+	var buildArgs []string
+	if o.Config.BuildMode != "" {
+		buildArgs = []string{o.Config.BuildMode}
+	}
 
-	extractedDir, err := o.unzipSource(tempDir.Name())
-	if err != nil {
-		log.Fatal(err)
+	fmt.Printf("Building %s...\n", o.Colored("Odin", "cyan"))
+	if err := o.buildSource(newPath, "build_odin.sh", version.Tag, buildArgs, nil); err != nil {
+		return err
 	}
+	fmt.Println(o.Colored("Build successful!\n", "green"))
 
-	outPath := filepath.Join(o.baseDir, extractedDir)
-	newPath := filepath.Join(o.baseDir, "ols")
+	o.linkShared(version.Tag)
 
-	if _, err = os.Stat(newPath); err == nil {
-		if o.Verbose {
-			fmt.Printf("Destination directory `%s` exists. Removing it.\n", newPath)
-		}
+	manifest := newManifest(version, newPath, archiveSum, o.Config.buildMode(), buildArgs, started)
+	if manifest.TreeDigest, err = o.recordTree(version.Tag); err != nil {
+		log.Warn("Failed to record install tree", "err", err)
+	}
+	if err := o.writeManifest(manifest); err != nil {
+		log.Warn("Failed to write install manifest", "err", err)
+	}
 
-		if err = os.RemoveAll(newPath); err != nil {
-			log.Warn("Failed to remove existing directory", "dir", newPath)
+	// only the newest install follows latest
+	if version.Tracks == "latest" {
+		for tag, info := range o.Config.Installs {
+			if info.Tracks == "latest" {
+				info.Tracks = ""
+				o.Config.Installs[tag] = info
+			}
 		}
 	}
 
-	if err := os.Rename(outPath, newPath); err != nil {
-		log.Fatal(err)
-	}
+	o.Config.SetInstallInfo(version.Tag, InstallInfo{
+		SourceURL:   version.ZipUrl,
+		Commit:      version.Commit,
+		Tracks:      version.Tracks,
+		BuildMode:   o.Config.buildMode(),
+		PublishedAt: version.PublishedAt,
+		InstalledAt: manifest.InstalledAt,
+	})
 
-	if o.Verbose {
-		fmt.Printf("Moved extracted directory `%s` to `%s`\n", outPath, newPath)
+	if err := o.Config.AddInstalledVersion(version.Tag); err != nil {
+		return err
 	}
 
-	fmt.Printf("Building %s...\n", o.Colored("OLS", "cyan"))
-	if err := o.buildSource(newPath, "build.sh", []string{fmt.Sprintf("PATH=%s:%s", filepath.Join(o.baseDir, "bin"), os.ExpandEnv("$PATH"))}); err != nil {
-		log.Fatal(err)
+	if version.Tracks == "master" && version.Tag != "master" {
+		return o.rotateMasterSnapshots(version.Tag)
 	}
-	fmt.Println(o.Colored("Build successful!\n", "green"))
-
-	olsBinary := filepath.Join(newPath, "ols")
-	o.createSymlink(olsBinary, "bin")
 
 	return nil
 }
 
 func (o *OVM) createSymlink(source, dest string) {
+	o.createNamedSymlink(source, dest, filepath.Base(source))
+}
+
+// createNamedSymlink links source to ~/.ovm/<dest>/<name>.
+func (o *OVM) createNamedSymlink(source, dest, name string) {
 	parentDir := filepath.Join(o.baseDir, dest)
-	destination := filepath.Join(parentDir, filepath.Base(source))
+	destination := filepath.Join(parentDir, name)
 
 	if _, err := os.Stat(parentDir); errors.Is(err, os.ErrNotExist) {
 		if err := os.MkdirAll(parentDir, os.ModePerm); err != nil {
@@ -229,6 +178,16 @@ func (o *OVM) unzipSource(source string) (string, error) {
 		return "", err
 	}
 
+	if len(reader.File) == 0 {
+		return "", fmt.Errorf("%s is empty", source)
+	}
+
+	// a partially extracted directory is removed, unless it was already there
+	top, _, _ := strings.Cut(reader.File[0].Name, "/")
+	topPath := filepath.Join(destination, top)
+	_, statErr := os.Stat(topPath)
+	partial := errors.Is(statErr, os.ErrNotExist) && filepath.Dir(topPath) == destination
+
 	for _, f := range reader.File {
 		err := unzipFile(f, destination)
 
@@ -237,6 +196,9 @@ func (o *OVM) unzipSource(source string) (string, error) {
 		}
 
 		if err != nil {
+			if partial {
+				os.RemoveAll(topPath)
+			}
 			return "", err
 		}
 	}
@@ -280,18 +242,24 @@ func unzipFile(f *zip.File, destination string) error {
 	return nil
 }
 
-func (o *OVM) buildSource(root, buildScript string, env []string) error {
-	var cmd exec.Cmd
+// buildSource runs buildScript with args inside root. The combined output is
+// written to ~/.ovm/logs/<logName>.log so failed builds can be inspected.
+func (o *OVM) buildSource(root, buildScript, logName string, args, env []string) error {
+	cmd := exec.Command(filepath.Join(root, buildScript), args...)
 	cmd.Dir = root
-	cmd.Path = filepath.Join(root, buildScript)
+	cmd.Env = append(os.Environ(), env...)
 
-	if len(env) > 0 {
-		cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	logPath, logErr := o.writeBuildLog(logName, output)
+	if logErr != nil {
+		log.Warn("Failed to write build log", "err", logErr)
 	}
 
-	output, err := cmd.Output()
 	if err != nil {
-		return err
+		if logErr != nil {
+			return fmt.Errorf("%s failed: %w", buildScript, err)
+		}
+		return fmt.Errorf("%s failed: %w (see %s)", buildScript, err, logPath)
 	}
 
 	if o.Verbose {
@@ -304,9 +272,18 @@ func (o *OVM) buildSource(root, buildScript string, env []string) error {
 func (o *OVM) linkCollections(version string) {
 	// version-specific directories
 	coreV := filepath.Join(o.baseDir, version, "core")
-	sharedV := filepath.Join(o.baseDir, version, "shared")
 	vendorV := filepath.Join(o.baseDir, version, "vendor")
 
+	o.createSymlink(coreV, "collections")
+	o.createSymlink(vendorV, "collections")
+	o.linkShared(version)
+}
+
+// linkShared replaces the shared collection of version with a link to the
+// persistent one in ~/.ovm/collections.
+func (o *OVM) linkShared(version string) {
+	sharedV := filepath.Join(o.baseDir, version, "shared")
+
 	// persistent shared collection
 	sharedP := filepath.Join(o.baseDir, "collections", "shared")
 
@@ -320,7 +297,5 @@ func (o *OVM) linkCollections(version string) {
 		log.Fatal(err)
 	}
 
-	o.createSymlink(coreV, "collections")
-	o.createSymlink(vendorV, "collections")
 	o.createSymlink(sharedP, version)
 }
@@ -2,30 +2,223 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
 
+// RemoteFilter narrows down the releases listed by `ovm ls -r`.
+type RemoteFilter struct {
+	Since             time.Time
+	Limit             int
+	IncludePrerelease bool
+	NotInstalled      bool
+}
+
+// ParseSince parses the --since flag, a month like 2024-01 or a full date.
+func ParseSince(since string) (time.Time, error) {
+	for _, layout := range []string{"2006-01", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, since, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM or YYYY-MM-DD", since)
+}
+
+func (f RemoteFilter) matches(o *OVM, rel GithubRelease) bool {
+	if rel.Draft || (rel.Prerelease && !f.IncludePrerelease) {
+		return false
+	}
+
+	if !f.Since.IsZero() && rel.PublishedAt.Before(f.Since) {
+		return false
+	}
+
+	return !f.NotInstalled || !o.IsInstalled(rel.TagName)
+}
+
 func (o *OVM) ListVersions(remote bool) error {
 	if remote {
-		versions, err := GetGitHubReleases("odin-lang", "odin")
-		if err != nil {
-			log.Fatal(err)
+		return o.ListRemoteVersions(RemoteFilter{})
+	}
+
+	active := o.ResolveVersion().Version
+	records := make([]VersionRecord, 0, len(o.Config.InstalledVersions))
+	for _, v := range o.sortedInstalledVersions() {
+		records = append(records, o.versionRecord(v, active))
+	}
+
+	if o.Output != OutputPlain {
+		return o.printVersionRecords(records)
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No Odin versions installed. Run `ovm i` to install the latest release.")
+		return nil
+	}
+
+	fmt.Println("Odin versions installed locally (*active):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, rec := range records {
+		// colors would throw off the column widths, so only the last column has them
+		marker := " "
+		if rec.Active {
+			marker = "*"
 		}
 
-		fmt.Println("Odin versions available for download:")
-		for _, v := range versions {
-			fmt.Printf("%s\n", v.TagName)
+		var status string
+		switch rec.Status {
+		case StatusMissing:
+			status = o.Colored("missing: install directory not found", "red")
+		case StatusBroken:
+			status = o.Colored("broken: odin binary not found", "red")
 		}
-	} else {
-		fmt.Println("Odin versions installed locally (*active):")
-		for _, v := range o.Config.InstalledVersions {
-			if v == o.Config.ActiveVersion {
-				fmt.Print("*")
+
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\n", marker, rec.Tag, formatSize(rec.Size),
+			formatDate(rec.InstalledAt), orDash(rec.BuildMode), status)
+	}
+
+	return w.Flush()
+}
+
+// sortedInstalledVersions returns the installed versions oldest first:
+// monthly releases by tag, followed by everything else by install date.
+func (o *OVM) sortedInstalledVersions() []string {
+	versions := append([]string(nil), o.Config.InstalledVersions...)
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		aRelease, bRelease := strings.HasPrefix(a, "dev-"), strings.HasPrefix(b, "dev-")
+
+		switch {
+		case aRelease && bRelease:
+			return a < b
+		case aRelease != bRelease:
+			return aRelease
+		default:
+			return o.Config.Installs[a].InstalledAt.Before(o.Config.Installs[b].InstalledAt)
+		}
+	})
+
+	return versions
+}
+
+func (o *OVM) ListRemoteVersions(filter RemoteFilter) error {
+	releases, err := GetGitHubReleases("odin-lang", "odin")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	active := o.ResolveVersion().Version
+	var records []VersionRecord
+	for _, rel := range releases {
+		if !filter.matches(o, rel) {
+			continue
+		}
+
+		if filter.Limit > 0 && len(records) == filter.Limit {
+			break
+		}
+
+		rec := o.versionRecord(rel.TagName, active)
+		rec.SourceURL = rel.ZipballURL
+		rec.PublishedAt = timeOrNil(rel.PublishedAt)
+		rec.Prerelease = rel.Prerelease
+		rec.Prebuilt = hasPrebuiltAsset(rel)
+		records = append(records, rec)
+	}
+
+	if o.Output != OutputPlain {
+		return o.printVersionRecords(records)
+	}
+
+	if len(records) == 0 {
+		fmt.Println("No Odin versions match the given filters.")
+		return nil
+	}
+
+	fmt.Println("Odin versions available for download (*active, +installed):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, rec := range records {
+		marker := " "
+		if rec.Active {
+			marker = "*"
+		} else if rec.Installed {
+			marker = "+"
+		}
+
+		var notes []string
+		if rec.Prebuilt {
+			notes = append(notes, "prebuilt")
+		}
+		if rec.Prerelease {
+			notes = append(notes, o.Colored("prerelease", "yellow"))
+		}
+
+		fmt.Fprintf(w, "%s%s\t%s\t%s\n", marker, rec.Tag, formatDate(rec.PublishedAt), strings.Join(notes, ", "))
+	}
+
+	return w.Flush()
+}
+
+// prebuiltNames maps GOOS to the platform names used in Odin's release
+// asset file names.
+var prebuiltNames = map[string][]string{
+	"linux":   {"linux", "ubuntu"},
+	"darwin":  {"macos", "darwin"},
+	"windows": {"windows"},
+}
+
+// hasPrebuiltAsset reports whether rel ships a prebuilt archive for the
+// current platform.
+func hasPrebuiltAsset(rel GithubRelease) bool {
+	arch := runtime.GOARCH
+	for _, asset := range rel.Assets {
+		name := strings.ToLower(asset.Name)
+		if arch == "arm64" && !strings.Contains(name, "arm64") {
+			continue
+		}
+		if arch == "amd64" && strings.Contains(name, "arm64") {
+			continue
+		}
+
+		for _, platform := range prebuiltNames[runtime.GOOS] {
+			if strings.Contains(name, platform) {
+				return true
 			}
-			fmt.Println(v)
 		}
 	}
 
+	return false
+}
+
+func (o *OVM) printVersionRecords(records []VersionRecord) error {
+	if o.Output == OutputJSON {
+		if records == nil {
+			records = []VersionRecord{}
+		}
+		return printJSON(records)
+	}
+
+	rows := make([][]string, 0, len(records))
+	for _, rec := range records {
+		size := "-"
+		if rec.Installed {
+			size = formatSize(rec.Size)
+		}
+
+		rows = append(rows, []string{
+			rec.Tag, formatBool(rec.Installed), formatBool(rec.Active), orDash(rec.Status), size,
+			orDash(rec.BuildMode), formatDate(rec.PublishedAt), formatDate(rec.InstalledAt), orDash(rec.InstallPath),
+		})
+	}
+
+	printTable([]string{"TAG", "INSTALLED", "ACTIVE", "STATUS", "SIZE", "BUILD", "PUBLISHED", "INSTALLED AT", "PATH"}, rows)
 	return nil
 }
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lockName is the file in the ovm home that mutating commands lock. It
+// holds the pid of the process holding the lock and what it is doing.
+const lockName = "ovm.lock"
+
+// errLockBusy is returned by lockFile when another process holds the lock.
+var errLockBusy = errors.New("the ovm lock is held by another process")
+
+// Lock takes the cross-process lock on the ovm home before a command
+// changes it, so concurrent runs don't race on config.toml, the install
+// directories or the bin links. action tells other processes what this one
+// is doing, e.g. "installing dev-2024-04". With WaitForLock set, Lock waits
+// for the lock to be released instead of failing.
+func (o *OVM) Lock(action string) error {
+	return o.acquireLock(action, o.WaitForLock)
+}
+
+func (o *OVM) acquireLock(action string, wait bool) error {
+	if o.lock != nil {
+		return nil
+	}
+
+	f, err := os.OpenFile(filepath.Join(o.baseDir, lockName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = lockFile(f, false)
+	if errors.Is(err, errLockBusy) {
+		holder := lockHolder(f)
+		if !wait {
+			f.Close()
+			return fmt.Errorf("%s, wait for it to finish or pass --wait", holder)
+		}
+
+		fmt.Fprintf(os.Stderr, "%s, waiting for it to finish...\n", holder)
+		err = lockFile(f, true)
+	}
+
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), action)), 0)
+	}
+	o.lock = f
+
+	// another process may have changed the config while this one waited
+	return o.reloadConfig()
+}
+
+// lockHolder describes the process holding the lock from the contents of
+// the lock file.
+func lockHolder(f *os.File) string {
+	data, _ := io.ReadAll(io.NewSectionReader(f, 0, 4096))
+	pid, action, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if pid == "" || action == "" {
+		return "another ovm process is running"
+	}
+
+	return fmt.Sprintf("another ovm process (pid %s) is %s", pid, action)
+}
+
+// Unlock releases the lock taken by Lock, if any.
+func (o *OVM) Unlock() {
+	if o.lock == nil {
+		return
+	}
+
+	o.lock.Truncate(0)
+	unlockFile(o.lock)
+	o.lock.Close()
+	o.lock = nil
+}
+
+// reloadConfig reads config.toml again, dropping what was loaded before.
+func (o *OVM) reloadConfig() error {
+	previous := o.Config
+	o.Config = Config{basePath: previous.basePath}
+
+	if err := o.loadConfig(); err != nil {
+		o.Config = previous
+		if errors.Is(err, ErrNoConfig) {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
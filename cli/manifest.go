@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"ovm/cli/meta"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// manifestName is the file in each install directory that records how the
+// install was made.
+const manifestName = ".ovm-manifest.json"
+
+// Manifest is the provenance of an install, written when it is built.
+type Manifest struct {
+	Version       string     `json:"version"`
+	SourceURL     string     `json:"source_url"`
+	Commit        string     `json:"commit"`
+	ArchiveSHA256 string     `json:"archive_sha256"`
+	BuildMode     string     `json:"build_mode"`
+	BuildCommand  string     `json:"build_command"`
+	Clang         string     `json:"clang_version"`
+	LLVM          string     `json:"llvm_version"`
+	OVMVersion    string     `json:"ovm_version"`
+	PublishedAt   *time.Time `json:"published_at"`
+	BuildStarted  time.Time  `json:"build_started_at"`
+	InstalledAt   time.Time  `json:"installed_at"`
+	OdinReport    string     `json:"odin_report"`
+	// TreeDigest is the digest of the .ovm-tree file listing the hash of
+	// every file in the install
+	TreeDigest string `json:"tree_digest"`
+}
+
+// newManifest records everything known about a finished build of version in
+// installPath.
+func newManifest(version TargetVersion, installPath, archiveSum, buildMode string, buildArgs []string, started time.Time) Manifest {
+	return Manifest{
+		Version:       version.Tag,
+		SourceURL:     version.ZipUrl,
+		Commit:        version.Commit,
+		ArchiveSHA256: archiveSum,
+		BuildMode:     buildMode,
+		BuildCommand:  strings.Join(append([]string{"build_odin.sh"}, buildArgs...), " "),
+		Clang:         firstLine(commandOutput("clang", "--version")),
+		LLVM:          commandOutput(llvmConfig(), "--version"),
+		OVMVersion:    meta.VERSION,
+		PublishedAt:   timeOrNil(version.PublishedAt),
+		BuildStarted:  started,
+		InstalledAt:   time.Now(),
+		OdinReport:    commandOutput(filepath.Join(installPath, "odin"+exeSuffix), "report"),
+	}
+}
+
+// llvmConfig returns the llvm-config the Odin build script would pick up.
+func llvmConfig() string {
+	if path := os.Getenv("LLVM_CONFIG"); path != "" {
+		return path
+	}
+	return "llvm-config"
+}
+
+// commandOutput runs name with args and returns its trimmed output, or "" if
+// it can't be run.
+func commandOutput(name string, args ...string) string {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func (o *OVM) writeManifest(m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(o.baseDir, m.Version, manifestName), append(data, '\n'), 0644)
+}
+
+// readManifest loads the manifest of an installed version. Installs made by
+// older versions of ovm don't have one.
+func (o *OVM) readManifest(version string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(o.baseDir, version, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// notesStyles are the lipgloss styles used to render release notes.
+type notesStyles struct {
+	title, heading, bold, code, link, bullet lipgloss.Style
+}
+
+func (o *OVM) notesStyles() notesStyles {
+	if !o.Config.UseColor {
+		plain := lipgloss.NewStyle()
+		return notesStyles{plain, plain, plain, plain, plain, plain}
+	}
+
+	return notesStyles{
+		title:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2")).Underline(true),
+		heading: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6")),
+		bold:    lipgloss.NewStyle().Bold(true),
+		code:    lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		link:    lipgloss.NewStyle().Underline(true),
+		bullet:  lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+	}
+}
+
+// Notes prints the release notes of version, defaulting to the version
+// selected for the current directory.
+func (o *OVM) Notes(version string) error {
+	if version == "" {
+		version = o.ResolveVersion().Version
+	}
+	version = o.ResolveAlias(version)
+
+	releases, err := GetGitHubReleases("odin-lang", "Odin")
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range releases {
+		if rel.TagName == version {
+			o.printNotes(rel)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no release notes found for %q, only monthly releases have them", version)
+}
+
+// NotesSince prints the release notes of every release newer than version,
+// oldest first.
+func (o *OVM) NotesSince(version string) error {
+	version = o.ResolveAlias(version)
+
+	releases, err := GetGitHubReleases("odin-lang", "Odin")
+	if err != nil {
+		return err
+	}
+
+	newer := -1
+	for i, rel := range releases {
+		if rel.TagName == version {
+			newer = i
+			break
+		}
+	}
+
+	if newer == -1 {
+		return fmt.Errorf("%q is not a known release", version)
+	}
+
+	if newer == 0 {
+		fmt.Printf("%s is the newest release.\n", version)
+		return nil
+	}
+
+	for i := newer - 1; i >= 0; i-- {
+		if releases[i].Draft {
+			continue
+		}
+
+		o.printNotes(releases[i])
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func (o *OVM) printNotes(rel GithubRelease) {
+	styles := o.notesStyles()
+
+	title := rel.TagName
+	if rel.Name != "" && rel.Name != rel.TagName {
+		title = fmt.Sprintf("%s (%s)", rel.Name, rel.TagName)
+	}
+
+	fmt.Println(styles.title.Render(title))
+	if !rel.PublishedAt.IsZero() {
+		fmt.Printf("Published %s\n", rel.PublishedAt.Local().Format("2006-01-02"))
+	}
+	fmt.Println()
+
+	body := strings.ReplaceAll(rel.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		fmt.Println(renderMarkdownLine(line, styles))
+	}
+}
+
+var (
+	mdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBullet  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdCode    = regexp.MustCompile("`([^`]+)`")
+	mdLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+// renderMarkdownLine styles the subset of markdown used in Odin's release
+// notes: headings, bullet lists, bold text, inline code and links.
+func renderMarkdownLine(line string, styles notesStyles) string {
+	if m := mdHeading.FindStringSubmatch(line); m != nil {
+		return styles.heading.Render(renderInline(m[2], styles))
+	}
+
+	if m := mdBullet.FindStringSubmatch(line); m != nil {
+		return m[1] + "  " + styles.bullet.Render("•") + " " + renderInline(m[2], styles)
+	}
+
+	return renderInline(line, styles)
+}
+
+func renderInline(text string, styles notesStyles) string {
+	text = mdCode.ReplaceAllStringFunc(text, func(s string) string {
+		return styles.code.Render(mdCode.FindStringSubmatch(s)[1])
+	})
+
+	text = mdBold.ReplaceAllStringFunc(text, func(s string) string {
+		return styles.bold.Render(mdBold.FindStringSubmatch(s)[1])
+	})
+
+	return mdLink.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		if m[1] == m[2] {
+			return styles.link.Render(m[2])
+		}
+		return fmt.Sprintf("%s (%s)", styles.link.Render(m[1]), m[2])
+	})
+}
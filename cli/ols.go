@@ -0,0 +1,294 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	olsOwner = "DanielGavin"
+	olsRepo  = "ols"
+)
+
+var ErrNoOLS = errors.New("OLS is not installed, run `ovm ols install` first")
+
+func (o *OVM) OLSInstall(ref string) error {
+	if err := o.installOLS(ref); err != nil {
+		return err
+	}
+
+	fmt.Println("Done! 🍻")
+	return nil
+}
+
+// installOLS downloads and builds OLS at ref against the active Odin version.
+func (o *OVM) installOLS(ref string) error {
+	if o.Config.ActiveVersion == "" {
+		return fmt.Errorf("no active Odin version, install one with `ovm i` before installing OLS")
+	}
+
+	commit, err := GetGitHubCommit(olsOwner, olsRepo, ref)
+	if err != nil {
+		return err
+	}
+
+	build := OLSBuild{
+		Ref:         ref,
+		Commit:      commit.SHA,
+		OdinVersion: o.Config.ActiveVersion,
+	}
+
+	if err := o.removeLegacyOLS(); err != nil {
+		return err
+	}
+
+	zipUrl := fmt.Sprintf("https://github.com/%s/%s/archive/%s.zip", olsOwner, olsRepo, commit.SHA)
+	label := o.Colored("OLS "+shortSHA(commit.SHA), "green")
+	outPath, _, err := o.fetchSource(zipUrl, "ols-"+commit.SHA, label)
+	if err != nil {
+		return err
+	}
+
+	newPath := filepath.Join(o.baseDir, "ols", build.Dir())
+	if err := o.moveInto(outPath, newPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Building %s for Odin %s...\n", o.Colored("OLS", "cyan"), build.OdinVersion)
+	odinPath := filepath.Join(o.baseDir, build.OdinVersion)
+	env := []string{fmt.Sprintf("PATH=%s%c%s", odinPath, os.PathListSeparator, os.Getenv("PATH"))}
+	if err := o.buildSource(newPath, "build.sh", "ols-"+build.Dir(), nil, env); err != nil {
+		return err
+	}
+
+	fmt.Printf("Building %s...\n", o.Colored("odinfmt", "cyan"))
+	if err := o.buildSource(newPath, "odinfmt.sh", "odinfmt-"+build.Dir(), nil, env); err != nil {
+		return err
+	}
+	fmt.Println(o.Colored("Build successful!\n", "green"))
+
+	build.BuiltAt = time.Now()
+	return o.activateOLS(build)
+}
+
+func (o *OVM) activateOLS(build OLSBuild) error {
+	if err := o.Config.SetOLSBuild(build); err != nil {
+		return err
+	}
+
+	o.installShims()
+	o.linkOLSConfig()
+	return nil
+}
+
+// removeLegacyOLS deletes the single OLS checkout older versions of ovm
+// built directly into ~/.ovm/ols.
+func (o *OVM) removeLegacyOLS() error {
+	olsDir := filepath.Join(o.baseDir, "ols")
+	if _, err := os.Stat(filepath.Join(olsDir, "build.sh")); err != nil {
+		return nil
+	}
+
+	if o.Verbose {
+		fmt.Printf("Removing legacy OLS install at `%s`\n", olsDir)
+	}
+
+	return os.RemoveAll(olsDir)
+}
+
+func (o *OVM) OLSUpdate() error {
+	build, ok := o.Config.ActiveOLSBuild()
+	if !ok {
+		return ErrNoOLS
+	}
+
+	commit, err := GetGitHubCommit(olsOwner, olsRepo, build.Ref)
+	if err != nil {
+		return err
+	}
+
+	if commit.SHA == build.Commit && build.OdinVersion == o.Config.ActiveVersion {
+		fmt.Printf("OLS %s is already up to date (%s).\n", build.Ref, shortSHA(build.Commit))
+		return nil
+	}
+
+	fmt.Printf("Updating OLS %s: %s -> %s\n", build.Ref, shortSHA(build.Commit), o.Colored(shortSHA(commit.SHA), "green"))
+	return o.OLSInstall(build.Ref)
+}
+
+// OLSUse switches to a cached OLS build for ref and the active Odin version,
+// building it first if there is none.
+func (o *OVM) OLSUse(ref string) error {
+	if b, ok := o.findOLSBuild(ref, o.Config.ActiveVersion); ok {
+		if err := o.activateOLS(b); err != nil {
+			return err
+		}
+
+		fmt.Printf("Active OLS set to %s (%s)\n", o.Colored(b.Ref, "green"), shortSHA(b.Commit))
+		return nil
+	}
+
+	return o.OLSInstall(ref)
+}
+
+// findOLSBuild looks for a cached build of ref (a ref name or a commit
+// prefix) made with odinVersion.
+func (o *OVM) findOLSBuild(ref, odinVersion string) (OLSBuild, bool) {
+	for _, b := range o.Config.OLS.Builds {
+		if b.OdinVersion != odinVersion {
+			continue
+		}
+
+		if b.Ref != ref && !(len(ref) >= 7 && strings.HasPrefix(b.Commit, ref)) {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(o.baseDir, "ols", b.Dir(), "ols")); err != nil {
+			continue
+		}
+
+		return b, true
+	}
+
+	return OLSBuild{}, false
+}
+
+// syncOLS makes sure the active OLS was built with the active Odin version
+// when automatic rebuilds are enabled, switching to a cached build for the
+// pair or rebuilding OLS when there is none.
+func (o *OVM) syncOLS() error {
+	if !o.Config.OLS.AutoRebuild {
+		return nil
+	}
+
+	build, ok := o.Config.ActiveOLSBuild()
+	if !ok || build.OdinVersion == o.Config.ActiveVersion {
+		return nil
+	}
+
+	if b, ok := o.findOLSBuild(build.Ref, o.Config.ActiveVersion); ok {
+		if o.Verbose {
+			fmt.Printf("Switching to cached OLS build %s (%s)\n", b.Ref, shortSHA(b.Commit))
+		}
+		return o.activateOLS(b)
+	}
+
+	fmt.Printf("OLS was built for %s, rebuilding it for %s...\n", build.OdinVersion, o.Config.ActiveVersion)
+	return o.installOLS(build.Ref)
+}
+
+func (o *OVM) OLSRemove() error {
+	if len(o.Config.OLS.Builds) == 0 {
+		if _, err := os.Stat(filepath.Join(o.baseDir, "ols")); errors.Is(err, os.ErrNotExist) {
+			return ErrNoOLS
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(o.baseDir, "ols")); err != nil {
+		return err
+	}
+
+	for _, bin := range []string{"ols", "odinfmt"} {
+		if err := os.Remove(filepath.Join(o.baseDir, "bin", bin)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(o.cacheDir, "ols-*.zip"))
+	for _, a := range archives {
+		os.Remove(a)
+	}
+
+	o.Config.OLS.Active = ""
+	o.Config.OLS.Builds = nil
+	if err := o.Config.save(); err != nil {
+		return err
+	}
+
+	fmt.Println("✔ Uninstalled OLS.")
+	return nil
+}
+
+func (o *OVM) OLSStatus() error {
+	build, ok := o.Config.ActiveOLSBuild()
+	if !ok {
+		return ErrNoOLS
+	}
+
+	fmt.Printf("OLS %s @ %s\n", o.Colored(build.Ref, "green"), shortSHA(build.Commit))
+	fmt.Printf("  Built:        %s\n", build.BuiltAt.Local().Format(time.DateTime))
+	fmt.Printf("  Built with:   Odin %s\n", build.OdinVersion)
+
+	if build.OdinVersion != o.Config.ActiveVersion {
+		fmt.Printf("  %s OLS was built for %s but the active Odin version is %s. Run `ovm ols update` to rebuild it.\n",
+			o.Colored("!", "yellow"), build.OdinVersion, o.Config.ActiveVersion)
+	}
+
+	if len(o.Config.OLS.Builds) > 1 {
+		fmt.Println("Cached builds (*active):")
+		for _, b := range o.Config.OLS.Builds {
+			if b.Dir() == o.Config.OLS.Active {
+				fmt.Print("*")
+			}
+			fmt.Printf("%s @ %s (Odin %s)\n", b.Ref, shortSHA(b.Commit), b.OdinVersion)
+		}
+	}
+
+	return nil
+}
+
+// Format runs the odinfmt built for the selected Odin version with args and
+// returns its exit code.
+func (o *OVM) Format(args []string) (int, error) {
+	odinfmt, err := o.resolveBinary("odinfmt", o.ResolveVersion())
+	if err != nil {
+		return 1, err
+	}
+
+	return runPassthrough(exec.Command(odinfmt, args...))
+}
+
+// olsBuildFor returns the OLS build to use with odinVersion: the active
+// build if it matches, another cached build for that version, or the active
+// build as a last resort.
+func (o *OVM) olsBuildFor(odinVersion string) (OLSBuild, bool) {
+	active, ok := o.Config.ActiveOLSBuild()
+	if ok && active.OdinVersion == odinVersion {
+		return active, true
+	}
+
+	for _, b := range o.Config.OLS.Builds {
+		if b.OdinVersion != odinVersion {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(o.baseDir, "ols", b.Dir())); err == nil {
+			return b, true
+		}
+	}
+
+	return active, ok
+}
+
+// runPassthrough runs cmd attached to the current terminal and returns the
+// exit code it finished with.
+func runPassthrough(cmd *exec.Cmd) (int, error) {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, err
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const olsSchemaURL = "https://raw.githubusercontent.com/DanielGavin/ols/master/misc/ols.schema.json"
+
+// ovmCollections are the collections kept up to date by linkCollections.
+var ovmCollections = []string{"core", "vendor", "shared"}
+
+type FormatterConfig struct {
+	CharacterWidth int
+	UseSpaces      bool
+	IndentWidth    int
+}
+
+// settings returns the formatter settings set in the config, which replace
+// the ones in an existing ols.json, and the defaults, which only fill in
+// settings that are missing.
+func (f FormatterConfig) settings() (set, defaults map[string]any) {
+	set = make(map[string]any)
+	if f.CharacterWidth != 0 {
+		set["character_width"] = f.CharacterWidth
+	}
+
+	indent := f.IndentWidth
+	if indent != 0 {
+		set["tabs_width"] = indent
+	} else {
+		indent = 4
+	}
+
+	if f.UseSpaces {
+		set["tabs"] = false
+		set["spaces"] = indent
+	}
+
+	defaults = map[string]any{
+		"character_width": 100,
+		"tabs":            true,
+		"tabs_width":      4,
+	}
+
+	return set, defaults
+}
+
+// OLSConfigure writes or updates an ols.json pointing OLS at the collections
+// managed by ovm. With global set, the file is shared by every OLS build,
+// otherwise it is written to the current directory. Entries that ovm does not
+// manage are kept as they are.
+func (o *OVM) OLSConfigure(global bool) error {
+	var path string
+	if global {
+		path = filepath.Join(o.baseDir, "ols", "ols.json")
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(cwd, "ols.json")
+	}
+
+	olsConfig := make(map[string]any)
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(existing, &olsConfig); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if _, ok := olsConfig["$schema"]; !ok {
+		olsConfig["$schema"] = olsSchemaURL
+	}
+
+	if _, ok := olsConfig["enable_format"]; !ok {
+		olsConfig["enable_format"] = true
+	}
+
+	olsConfig["collections"] = o.mergeCollections(olsConfig["collections"])
+
+	formatter, _ := olsConfig["formatter"].(map[string]any)
+	if formatter == nil {
+		formatter = make(map[string]any)
+	}
+	set, defaults := o.Config.OLS.Formatter.settings()
+	for k, v := range defaults {
+		if _, ok := formatter[k]; !ok {
+			formatter[k] = v
+		}
+	}
+	for k, v := range set {
+		formatter[k] = v
+	}
+	// spaces are only ever written for UseSpaces, so turning it off undoes them
+	if _, ok := formatter["spaces"]; ok && !o.Config.OLS.Formatter.UseSpaces {
+		delete(formatter, "spaces")
+		formatter["tabs"] = true
+	}
+	olsConfig["formatter"] = formatter
+
+	serialized, err := json.MarshalIndent(olsConfig, "", "\t")
+	if err != nil {
+		return err
+	}
+	serialized = append(serialized, '\n')
+
+	if bytes.Equal(existing, serialized) {
+		fmt.Printf("%s is already up to date.\n", path)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0775); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, serialized, 0644); err != nil {
+		return err
+	}
+
+	if global {
+		o.linkOLSConfig()
+	}
+
+	fmt.Printf("Wrote %s\n", o.Colored(path, "green"))
+	return nil
+}
+
+// mergeCollections replaces the ovm-managed entries in an ols.json
+// collection list and keeps every other entry in place.
+func (o *OVM) mergeCollections(current any) []any {
+	entries, _ := current.([]any)
+	managed := make(map[string]bool)
+
+	for _, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _ := entry["name"].(string)
+		for _, c := range ovmCollections {
+			if name == c {
+				entry["path"] = filepath.Join(o.baseDir, "collections", c)
+				managed[c] = true
+			}
+		}
+	}
+
+	for _, c := range ovmCollections {
+		if !managed[c] {
+			entries = append(entries, map[string]any{
+				"name": c,
+				"path": filepath.Join(o.baseDir, "collections", c),
+			})
+		}
+	}
+
+	return entries
+}
+
+// linkOLSConfig links the global ols.json next to every OLS binary, which is
+// where OLS looks for its global configuration.
+func (o *OVM) linkOLSConfig() {
+	global := filepath.Join(o.baseDir, "ols", "ols.json")
+	if _, err := os.Stat(global); err != nil {
+		return
+	}
+
+	for _, b := range o.Config.OLS.Builds {
+		if _, err := os.Stat(filepath.Join(o.baseDir, "ols", b.Dir())); err == nil {
+			o.createSymlink(global, filepath.Join("ols", b.Dir()))
+		}
+	}
+}
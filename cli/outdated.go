@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// updateCheckInterval throttles the update notice shown after commands.
+const updateCheckInterval = 24 * time.Hour
+
+// stableReleases drops drafts and prereleases from releases, keeping the
+// newest-first order.
+func stableReleases(releases []GithubRelease) []GithubRelease {
+	var stable []GithubRelease
+	for _, rel := range releases {
+		if !rel.Draft && !rel.Prerelease {
+			stable = append(stable, rel)
+		}
+	}
+
+	return stable
+}
+
+// releasesBehind returns how many stable releases are newer than tag, or -1
+// if tag isn't one of them.
+func releasesBehind(releases []GithubRelease, tag string) int {
+	for i, rel := range releases {
+		if rel.TagName == tag {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Outdated compares every installed version with the newest release, and
+// master installs with the remote head of master.
+func (o *OVM) Outdated() error {
+	releases, err := GetGitHubReleases("odin-lang", "Odin")
+	if err != nil {
+		return err
+	}
+
+	releases = stableReleases(releases)
+	if len(releases) == 0 {
+		return fmt.Errorf("no Odin releases found")
+	}
+	newest := releases[0].TagName
+
+	installed := o.sortedInstalledVersions()
+	if len(installed) == 0 {
+		fmt.Printf("No Odin versions installed. The newest release is %s.\n", o.Colored(newest, "green"))
+		return nil
+	}
+
+	active := o.ResolveVersion().Version
+	fmt.Printf("Newest release: %s\n", o.Colored(newest, "green"))
+
+	for _, v := range installed {
+		marker := " "
+		if v == active {
+			marker = "*"
+		}
+
+		fmt.Printf("%s%s: %s\n", marker, v, o.outdatedStatus(v, releases))
+	}
+
+	return nil
+}
+
+func (o *OVM) outdatedStatus(version string, releases []GithubRelease) string {
+	if strings.HasPrefix(version, "master") {
+		commit := o.Config.Installs[version].Commit
+		if commit == "" {
+			return "unknown commit, reinstall it with `ovm i master` to track it"
+		}
+
+		comparison, err := GetGitHubComparison("odin-lang", "Odin", commit, "master")
+		if err != nil {
+			return fmt.Sprintf("failed to compare with master: %v", err)
+		}
+
+		if comparison.AheadBy == 0 {
+			return o.Colored("up to date", "green") + fmt.Sprintf(" (%s)", shortSHA(commit))
+		}
+
+		return o.Colored(fmt.Sprintf("%d commits behind master", comparison.AheadBy), "yellow") + fmt.Sprintf(" (%s)", shortSHA(commit))
+	}
+
+	switch behind := releasesBehind(releases, version); behind {
+	case -1:
+		return "not a known release"
+	case 0:
+		return o.Colored("up to date", "green")
+	case 1:
+		return o.Colored("1 release behind", "yellow")
+	default:
+		return o.Colored(fmt.Sprintf("%d releases behind", behind), "yellow")
+	}
+}
+
+// UpdateNotice prints a one-line notice to stderr when a newer release than
+// the active version exists. It checks at most once a day, only when
+// enabled in the config, and never outside an interactive terminal or in CI.
+func (o *OVM) UpdateNotice() {
+	if !o.Config.UpdateNotice || time.Since(o.Config.LastUpdateCheck) < updateCheckInterval {
+		return
+	}
+
+	if _, ci := os.LookupEnv("CI"); ci || !term.IsTerminal(int(os.Stderr.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+
+	active := o.ResolveVersion().Version
+	if !strings.HasPrefix(active, "dev-") {
+		return
+	}
+
+	// the notice isn't worth waiting for another ovm process
+	if err := o.acquireLock("checking for updates", false); err != nil {
+		return
+	}
+	defer o.Unlock()
+
+	if time.Since(o.Config.LastUpdateCheck) < updateCheckInterval {
+		return
+	}
+
+	o.Config.LastUpdateCheck = time.Now()
+	if err := o.Config.save(); err != nil {
+		return
+	}
+
+	releases, err := GetGitHubReleases("odin-lang", "Odin")
+	if err != nil {
+		return
+	}
+
+	releases = stableReleases(releases)
+	if len(releases) > 0 && releasesBehind(releases, active) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%s is available (you have %s). Run `ovm outdated` for details.\n",
+			o.Colored(releases[0].TagName, "green"), active)
+	}
+}
@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Formats accepted by the global --output flag.
+const (
+	OutputPlain = "plain"
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+func ValidateOutput(format string) error {
+	switch format {
+	case OutputPlain, OutputTable, OutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q, expected json, table or plain", format)
+	}
+}
+
+// VersionRecord describes one Odin version in structured output. Fields
+// are always present; unknown values are null, empty or zero.
+type VersionRecord struct {
+	Tag         string     `json:"tag"`
+	Installed   bool       `json:"installed"`
+	Active      bool       `json:"active"`
+	InstallPath string     `json:"install_path"`
+	Size        int64      `json:"size"`
+	BuildMode   string     `json:"build_mode"`
+	SourceURL   string     `json:"source_url"`
+	PublishedAt *time.Time `json:"published_at"`
+	Prerelease  bool       `json:"prerelease"`
+	Prebuilt    bool       `json:"prebuilt"`
+	InstalledAt *time.Time `json:"installed_at"`
+	// Status is "ok", "broken" or "missing" for installed versions
+	Status string `json:"status"`
+}
+
+// Install states reported in VersionRecord.Status.
+const (
+	StatusOK      = "ok"
+	StatusBroken  = "broken"
+	StatusMissing = "missing"
+)
+
+// CurrentRecord is the structured output of `ovm current`.
+type CurrentRecord struct {
+	VersionRecord
+	Source     string `json:"source"`
+	SourcePath string `json:"source_path"`
+	Alias      string `json:"alias"`
+}
+
+// InfoRecord is the structured output of `ovm info`. Manifest is null for
+// installs made before manifests were recorded.
+type InfoRecord struct {
+	VersionRecord
+	Manifest *Manifest `json:"manifest"`
+}
+
+// versionRecord collects what is known locally about tag, where active is
+// the version selected for the current directory.
+func (o *OVM) versionRecord(tag, active string) VersionRecord {
+	rec := VersionRecord{
+		Tag:       tag,
+		Installed: o.IsInstalled(tag),
+		Active:    tag == active,
+	}
+
+	if !rec.Installed {
+		return rec
+	}
+
+	rec.InstallPath = filepath.Join(o.baseDir, tag)
+	rec.Size = dirSize(rec.InstallPath)
+	rec.Status = installStatus(rec.InstallPath)
+
+	if info, ok := o.Config.Installs[tag]; ok {
+		rec.BuildMode = info.BuildMode
+		rec.SourceURL = info.SourceURL
+		rec.PublishedAt = timeOrNil(info.PublishedAt)
+		rec.InstalledAt = timeOrNil(info.InstalledAt)
+	}
+
+	return rec
+}
+
+// installStatus checks that an install directory and its odin binary exist.
+func installStatus(installPath string) string {
+	if _, err := os.Stat(installPath); err != nil {
+		return StatusMissing
+	}
+
+	if _, err := os.Stat(filepath.Join(installPath, "odin"+exeSuffix)); err != nil {
+		return StatusBroken
+	}
+
+	return StatusOK
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// dirSize returns the total size of the regular files under path. Symlinks
+// aren't followed, so the shared collection isn't counted.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+
+	return size
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.DateOnly)
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// printTable prints rows as aligned columns under header.
+func printTable(header []string, rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// Info prints what is known about version, defaulting to the version
+// selected for the current directory.
+func (o *OVM) Info(version string) error {
+	active := o.ResolveVersion().Version
+	if version == "" {
+		version = active
+	}
+	version = o.ResolveAlias(version)
+
+	if !o.IsInstalled(version) {
+		return fmt.Errorf("version %s is not installed", version)
+	}
+
+	rec := InfoRecord{VersionRecord: o.versionRecord(version, active)}
+	if m, err := o.readManifest(version); err == nil {
+		rec.Manifest = m
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Warn("Failed to read install manifest", "err", err)
+	}
+
+	var commit string
+	if rec.Manifest != nil {
+		commit = rec.Manifest.Commit
+	} else {
+		commit = o.Config.Installs[version].Commit
+	}
+
+	switch o.Output {
+	case OutputJSON:
+		return printJSON(rec)
+	case OutputTable:
+		printTable([]string{"TAG", "ACTIVE", "STATUS", "SIZE", "BUILD", "COMMIT", "PUBLISHED", "INSTALLED", "SOURCE", "PATH"}, [][]string{{
+			rec.Tag, formatBool(rec.Active), rec.Status, formatSize(rec.Size), orDash(rec.BuildMode), orDash(shortSHA(commit)),
+			formatDate(rec.PublishedAt), formatDate(rec.InstalledAt), orDash(rec.SourceURL), rec.InstallPath,
+		}})
+	default:
+		fmt.Printf("Odin %s\n", o.Colored(rec.Tag, "green"))
+		fmt.Printf("  Active:     %s\n", formatBool(rec.Active))
+		fmt.Printf("  Path:       %s\n", rec.InstallPath)
+		fmt.Printf("  Size:       %s\n", formatSize(rec.Size))
+		fmt.Printf("  Build mode: %s\n", orDash(rec.BuildMode))
+		fmt.Printf("  Source:     %s\n", orDash(rec.SourceURL))
+		fmt.Printf("  Commit:     %s\n", orDash(commit))
+		fmt.Printf("  Published:  %s\n", formatDate(rec.PublishedAt))
+		fmt.Printf("  Installed:  %s\n", formatDate(rec.InstalledAt))
+		fmt.Printf("  Status:     %s\n", rec.Status)
+
+		m := rec.Manifest
+		if m == nil {
+			fmt.Println("  No install manifest, reinstall this version to record one.")
+			return nil
+		}
+
+		fmt.Printf("  Archive:    %s\n", orDash(m.ArchiveSHA256))
+		fmt.Printf("  Built with: %s\n", orDash(m.BuildCommand))
+		fmt.Printf("  Clang:      %s\n", orDash(m.Clang))
+		fmt.Printf("  LLVM:       %s\n", orDash(m.LLVM))
+		fmt.Printf("  ovm:        %s\n", orDash(m.OVMVersion))
+		fmt.Printf("  Build time: %s\n", m.InstalledAt.Sub(m.BuildStarted).Round(time.Second))
+
+		if m.OdinReport != "" {
+			fmt.Println("\nodin report:")
+			for _, line := range strings.Split(m.OdinReport, "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	return nil
+}
@@ -8,22 +8,30 @@ import (
 	"path/filepath"
 
 	"github.com/charmbracelet/log"
-	"github.com/pelletier/go-toml/v2"
 )
 
 type OVM struct {
 	baseDir string
-	Verbose bool
-	Config  Config
+	// cacheDir holds downloaded archives, ~/.ovm/cache unless the XDG
+	// layout is used
+	cacheDir string
+	Verbose  bool
+	// Output is the format listing commands print in, one of the Output* constants
+	Output string
+	// WaitForLock makes Lock wait for other ovm processes instead of failing
+	WaitForLock bool
+	Config      Config
+	// lock is the lock file while this process holds it
+	lock *os.File
 }
 
 func Initialize(verbose bool) *OVM {
-	home, err := os.UserHomeDir()
+	layout, err := findHome()
 	if err != nil {
-		home = "~"
+		log.Fatal(err)
 	}
 
-	ovmPath := filepath.Join(home, ".ovm")
+	ovmPath := layout.Base
 	if _, err := os.Stat(ovmPath); errors.Is(err, fs.ErrNotExist) {
 		if verbose {
 			fmt.Printf("OVM directory not found at `%s`, creating it now\n", ovmPath)
@@ -34,39 +42,85 @@ func Initialize(verbose bool) *OVM {
 		}
 	}
 
+	if err := os.MkdirAll(filepath.Dir(layout.Config), 0775); err != nil {
+		log.Fatal(err)
+	}
+
 	ovm := &OVM{
-		baseDir: ovmPath,
-		Verbose: verbose,
+		baseDir:  ovmPath,
+		cacheDir: layout.Cache,
+		Verbose:  verbose,
+		Output:   OutputPlain,
 	}
-	ovm.Config.basePath = filepath.Join(ovmPath, "config.toml")
+	ovm.Config.basePath = layout.Config
 
 	if err := ovm.loadConfig(); err != nil {
-		if errors.Is(err, ErrNoConfig) {
-			if ovm.Verbose {
-				fmt.Println("Config file not found. Creating default.")
-			}
+		if !errors.Is(err, ErrNoConfig) {
+			log.Fatal("Failed to load config, fix or remove the file", "err", err)
+		}
 
-			ovm.Config.UseColor = true
+		if ovm.Verbose {
+			fmt.Println("Config file not found. Creating default.")
+		}
+
+		ovm.Config.UseColor = true
+		ovm.Config.SchemaVersion = configSchemaVersion
 
-			if err := ovm.Config.save(); err != nil {
-				log.Warn("Failed to create config.toml file", err)
-			}
+		if err := ovm.Config.save(); err != nil {
+			log.Warn("Failed to create config.toml file", err)
 		}
 	}
 
 	return ovm
 }
 
+// loadConfig reads config.toml, saving it in the current schema (with a
+// backup of the original) when it was written with an older one.
 func (o *OVM) loadConfig() error {
+	data, version, err := o.readConfig()
+	if err != nil || version == configSchemaVersion {
+		return err
+	}
+
+	backup, err := backupConfig(o.Config.basePath, data, version)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Migrated config", "path", o.Config.basePath, "schema_version", configSchemaVersion, "backup", filepath.Base(backup))
+	return o.Config.save()
+}
+
+// readConfig decodes config.toml into o.Config, migrating it in memory when
+// it was written with an older schema, and returns the data as read and its
+// schema version. It never writes anything.
+func (o *OVM) readConfig() ([]byte, int, error) {
 	set_path := o.Config.basePath
 	if _, err := os.Stat(set_path); errors.Is(err, os.ErrNotExist) {
-		return ErrNoConfig
+		return nil, 0, ErrNoConfig
 	}
 
 	data, err := os.ReadFile(set_path)
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+
+	migrated, version, err := migrateConfig(set_path, data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version == configSchemaVersion {
+		return data, version, decodeConfig(set_path, data, &o.Config)
+	}
+
+	if err := decodeConfig(set_path, migrated, &o.Config); err != nil {
+		// report problems at their lines in the file as it was written
+		if origErr := decodeConfig(set_path, data, &Config{}); origErr != nil {
+			return nil, 0, origErr
+		}
+		return nil, 0, fmt.Errorf("failed to load %s after migrating it from schema version %d: %w", set_path, version, err)
 	}
 
-	return toml.Unmarshal(data, &o.Config)
+	return data, version, nil
 }
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Files that pin an Odin version for a project, checked in this order in
+// every directory from the current one up to the filesystem root.
+const (
+	versionFileName = ".odin-version"
+	projectFileName = "ovm.toml"
+)
+
+var ErrNoProjectVersion = errors.New("no .odin-version or ovm.toml found in this directory or its parents")
+
+// Where a VersionSelection came from.
+const (
+	SourceEnv     = "env"
+	SourceProject = "project"
+	SourceGlobal  = "global"
+)
+
+// VersionSelection is a version together with where it was chosen.
+type VersionSelection struct {
+	Version string
+	Source  string
+	// Path is the project file that chose the version
+	Path string
+	// Alias is the alias that was resolved to Version, if any
+	Alias string
+}
+
+func (s VersionSelection) Describe() string {
+	var desc string
+	switch s.Source {
+	case SourceEnv:
+		desc = "set by OVM_VERSION"
+	case SourceProject:
+		desc = "set by " + s.Path
+	default:
+		desc = "global default"
+	}
+
+	if s.Alias != "" {
+		desc += " via alias " + s.Alias
+	}
+
+	return desc
+}
+
+// ResolveVersion picks the Odin version for the current environment: the
+// OVM_VERSION variable, the nearest project version file, or the global
+// active version, in that order.
+func (o *OVM) ResolveVersion() VersionSelection {
+	if version := os.Getenv("OVM_VERSION"); version != "" {
+		sel := o.resolveSelection(VersionSelection{Version: version, Source: SourceEnv})
+		err := validateVersion(sel.Version)
+		if err == nil {
+			return sel
+		}
+		log.Warn("Ignoring OVM_VERSION", "err", err)
+	}
+
+	if sel, err := findProjectVersion(); err == nil {
+		sel = o.resolveSelection(sel)
+		err := validateVersion(sel.Version)
+		if err == nil {
+			return sel
+		}
+		log.Warn("Ignoring project version", "path", sel.Path, "err", err)
+	} else if !errors.Is(err, ErrNoProjectVersion) {
+		log.Warn("Ignoring project version", "err", err)
+	}
+
+	return VersionSelection{Version: o.Config.ActiveVersion, Source: SourceGlobal}
+}
+
+// findProjectVersion walks up from the current directory looking for a
+// project version file.
+func findProjectVersion() (VersionSelection, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return VersionSelection{}, err
+	}
+
+	for {
+		versionFile := filepath.Join(dir, versionFileName)
+		if data, err := os.ReadFile(versionFile); err == nil {
+			version := parseVersionFile(data)
+			if version == "" {
+				return VersionSelection{}, fmt.Errorf("%s does not contain a version", versionFile)
+			}
+			if err := validateVersion(version); err != nil {
+				return VersionSelection{}, fmt.Errorf("%s: %w", versionFile, err)
+			}
+			return VersionSelection{Version: version, Source: SourceProject, Path: versionFile}, nil
+		}
+
+		projectFile := filepath.Join(dir, projectFileName)
+		if data, err := os.ReadFile(projectFile); err == nil {
+			var project struct {
+				Odin struct {
+					Version string `toml:"version"`
+				} `toml:"odin"`
+			}
+
+			if err := toml.Unmarshal(data, &project); err != nil {
+				return VersionSelection{}, fmt.Errorf("failed to parse %s: %w", projectFile, err)
+			}
+
+			if project.Odin.Version != "" {
+				if err := validateVersion(project.Odin.Version); err != nil {
+					return VersionSelection{}, fmt.Errorf("%s: %w", projectFile, err)
+				}
+				return VersionSelection{Version: project.Odin.Version, Source: SourceProject, Path: projectFile}, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return VersionSelection{}, ErrNoProjectVersion
+		}
+		dir = parent
+	}
+}
+
+// parseVersionFile returns the first line of a .odin-version file that
+// isn't blank or a # comment.
+func parseVersionFile(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// UseProjectVersion switches to the version pinned by the nearest project
+// version file.
+func (o *OVM) UseProjectVersion() error {
+	sel, err := findProjectVersion()
+	if err != nil {
+		return err
+	}
+
+	if o.Verbose {
+		fmt.Printf("Using %s from %s\n", sel.Version, sel.Path)
+	}
+
+	return o.Use(sel.Version)
+}
+
+// ProjectVersion returns the version pinned by the nearest project version
+// file, or "" when there is none.
+func ProjectVersion() string {
+	sel, err := findProjectVersion()
+	if err != nil {
+		return ""
+	}
+	return sel.Version
+}
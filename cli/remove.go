@@ -7,9 +7,11 @@ import (
 )
 
 func (o *OVM) Uninstall(version string) error {
+	version = o.ResolveAlias(version)
 	targetPath := filepath.Join(o.baseDir, version)
 
-	if _, err := os.Stat(targetPath); err == nil {
+	// only ever remove a direct child of the home that is a tracked install
+	if o.checkInstalled(version) == nil && filepath.Dir(targetPath) == filepath.Clean(o.baseDir) {
 		if err := os.RemoveAll(targetPath); err != nil {
 			return err
 		}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// configSchemaVersion is the schema_version written to config.toml. Bump it
+// and add a step to configMigrations whenever a change to Config would make
+// older files load differently, like renaming or retyping a field.
+const configSchemaVersion = 1
+
+// configMigrations upgrade the raw contents of config.toml one schema
+// version at a time; configMigrations[n] turns version n into n+1.
+var configMigrations = []func(raw map[string]any) error{
+	// 0 -> 1: configs written before schema_version existed. Every field
+	// added up to this point defaults to its zero value, so only the
+	// version is new.
+	func(raw map[string]any) error { return nil },
+}
+
+// migrateConfig upgrades config.toml data written with an older schema. It
+// returns the upgraded data together with the schema version the data was
+// written with, and the data unchanged when it is already current.
+func migrateConfig(path string, data []byte) ([]byte, int, error) {
+	raw := make(map[string]any)
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, 0, configError(path, err)
+	}
+
+	var version int
+	if v, ok := raw["schema_version"]; ok {
+		n, ok := v.(int64)
+		if !ok {
+			return nil, 0, fmt.Errorf("%s: schema_version must be a number", path)
+		}
+		version = int(n)
+	}
+
+	if version > configSchemaVersion {
+		return nil, 0, fmt.Errorf("%s uses schema version %d but this ovm only understands up to %d, upgrade ovm with `ovm upgrade`", path, version, configSchemaVersion)
+	}
+
+	if version == configSchemaVersion {
+		return data, version, nil
+	}
+
+	for v := version; v < configSchemaVersion; v++ {
+		if err := configMigrations[v](raw); err != nil {
+			return nil, 0, fmt.Errorf("failed to migrate %s from schema version %d: %w", path, v, err)
+		}
+	}
+	raw["schema_version"] = configSchemaVersion
+
+	migrated, err := toml.Marshal(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return migrated, version, nil
+}
+
+// backupConfig keeps config.toml data written with an older schema version
+// next to it before the migrated config replaces it, and returns the path of
+// the copy.
+func backupConfig(path string, data []byte, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up %s before migrating it: %w", path, err)
+	}
+
+	return backup, nil
+}
+
+// decodeConfig strictly decodes config.toml data into c. Unknown keys and
+// values of the wrong type are errors, reported with their line numbers.
+func decodeConfig(path string, data []byte, c *Config) error {
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return configError(path, decoder.Decode(c))
+}
+
+// configError adds the location of TOML decoding errors to err.
+func configError(path string, err error) error {
+	var strictErr *toml.StrictMissingError
+	if errors.As(err, &strictErr) {
+		lines := make([]string, 0, len(strictErr.Errors))
+		for _, e := range strictErr.Errors {
+			row, col := e.Position()
+			lines = append(lines, fmt.Sprintf("%s:%d:%d: unknown key %s", path, row, col, strings.Join(e.Key(), ".")))
+		}
+		return errors.New(strings.Join(lines, "\n"))
+	}
+
+	var decodeErr *toml.DecodeError
+	if errors.As(err, &decodeErr) {
+		row, col := decodeErr.Position()
+		return fmt.Errorf("%s:%d:%d: %s", path, row, col, strings.TrimPrefix(decodeErr.Error(), "toml: "))
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateAndDecodeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		version int
+		err     string
+	}{
+		{
+			name:    "old file",
+			data:    "UseColor = true\nActiveVersion = 'dev-2024-01'\n",
+			version: 0,
+		},
+		{
+			name:    "current file",
+			data:    "schema_version = 1\nUseColor = true\n",
+			version: 1,
+		},
+		{
+			name: "unknown key",
+			data: "schema_version = 1\nFoo = 1\n",
+			err:  "config.toml:2:1: unknown key Foo",
+		},
+		{
+			name: "wrong type",
+			data: "schema_version = 1\nUseColor = 'yes'\n",
+			err:  "config.toml:2:",
+		},
+		{
+			name: "newer schema_version",
+			data: "schema_version = 9\n",
+			err:  "uses schema version 9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, version, err := migrateConfig("config.toml", []byte(tt.data))
+			if err == nil {
+				var c Config
+				err = decodeConfig("config.toml", migrated, &c)
+			}
+
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != tt.version {
+				t.Errorf("got schema version %d, want %d", version, tt.version)
+			}
+			if !strings.Contains(string(migrated), "schema_version = 1") {
+				t.Errorf("migrated data has no current schema_version:\n%s", migrated)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sessionVars are the variables set by sessionEnv, in the order they are
+// printed by `ovm env`.
+var sessionVars = []string{"PATH", "ODIN_ROOT", "OVM_VERSION"}
+
+// sessionEnv returns the environment variables that select version for a
+// single command or shell session without touching the global config.
+func (o *OVM) sessionEnv(version string) map[string]string {
+	versionDir := filepath.Join(o.baseDir, version)
+
+	return map[string]string{
+		"PATH":        versionDir + string(os.PathListSeparator) + o.stripVersionDirs(os.Getenv("PATH")),
+		"ODIN_ROOT":   versionDir,
+		"OVM_VERSION": version,
+	}
+}
+
+// stripVersionDirs removes version directories added by an earlier
+// sessionEnv from a PATH list, so switching versions doesn't pile them up.
+func (o *OVM) stripVersionDirs(path string) string {
+	var kept []string
+	for _, dir := range filepath.SplitList(path) {
+		name := filepath.Base(dir)
+		if filepath.Dir(dir) == o.baseDir && name != "bin" && name != "self" {
+			continue
+		}
+		kept = append(kept, dir)
+	}
+
+	return strings.Join(kept, string(os.PathListSeparator))
+}
+
+// ensureInstalled offers to install version if it is missing and returns
+// the version that is installed, which is a master@<sha> snapshot when
+// master is installed with snapshots enabled. Unlike `ovm use`, installing
+// here doesn't change the active version. Install progress goes to stderr,
+// since the stdout of `ovm env` is evaluated by the shell.
+func (o *OVM) ensureInstalled(version string) (string, error) {
+	if err := validateVersion(version); err != nil {
+		return "", err
+	}
+
+	if o.IsInstalled(version) {
+		return version, nil
+	}
+
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	fmt.Fprintf(os.Stderr, "It looks like %s isn't installed. Would you like to install it? [y/n]\n", version)
+	if !GetConfirmation() {
+		return "", fmt.Errorf("Version %s is not installed", version)
+	}
+
+	// only held for the install, not while the command or shell runs
+	if err := o.Lock("installing " + version); err != nil {
+		return "", err
+	}
+	defer o.Unlock()
+
+	target := o.InstallTarget(version)
+	if err := o.buildVersion(target); err != nil {
+		return "", err
+	}
+
+	return target.Tag, nil
+}
+
+// Exec runs command with version first on PATH and returns its exit code.
+func (o *OVM) Exec(version string, command []string) (int, error) {
+	if len(command) == 0 {
+		return 1, fmt.Errorf("missing command. Usage: `ovm exec <version> -- <command>`")
+	}
+
+	version, err := o.ensureInstalled(o.ResolveAlias(version))
+	if err != nil {
+		return 1, err
+	}
+
+	for k, v := range o.sessionEnv(version) {
+		if err := os.Setenv(k, v); err != nil {
+			return 1, err
+		}
+	}
+
+	if o.Verbose {
+		fmt.Fprintf(os.Stderr, "Running %q with Odin %s\n", command, version)
+	}
+
+	return runPassthrough(exec.Command(command[0], command[1:]...))
+}
+
+// Env prints shell commands that select version for the current shell when
+// evaluated. An empty shell is detected from $SHELL.
+func (o *OVM) Env(version, shell string) error {
+	if shell == "" {
+		shell = detectShell()
+	}
+
+	version, err := o.ensureInstalled(o.ResolveAlias(version))
+	if err != nil {
+		return err
+	}
+
+	env := o.sessionEnv(version)
+	for _, k := range sessionVars {
+		line, err := exportLine(shell, k, env[k])
+		if err != nil {
+			return err
+		}
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// detectShell guesses the syntax `ovm env` should print from $SHELL.
+func detectShell() string {
+	switch name := filepath.Base(os.Getenv("SHELL")); name {
+	case "bash", "zsh", "fish":
+		return name
+	default:
+		return "posix"
+	}
+}
+
+func exportLine(shell, key, value string) (string, error) {
+	switch shell {
+	case "bash", "zsh", "posix", "sh":
+		return fmt.Sprintf("export %s=%s;", key, shellQuote(value)), nil
+	case "fish":
+		if key != "PATH" {
+			return fmt.Sprintf("set -gx %s %s;", key, shellQuote(value)), nil
+		}
+
+		var dirs []string
+		for _, dir := range filepath.SplitList(value) {
+			dirs = append(dirs, shellQuote(dir))
+		}
+		return fmt.Sprintf("set -gx PATH %s;", strings.Join(dirs, " ")), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q, expected bash, zsh, fish or posix", shell)
+	}
+}
+
+// shellQuote wraps s in single quotes, which every supported shell treats
+// literally.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Shell starts an interactive subshell with version selected and returns its
+// exit code once the user leaves it.
+func (o *OVM) Shell(version string) (int, error) {
+	version, err := o.ensureInstalled(o.ResolveAlias(version))
+	if err != nil {
+		return 1, err
+	}
+
+	shell := os.Getenv("SHELL")
+	if runtime.GOOS == "windows" {
+		shell = os.Getenv("COMSPEC")
+	}
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	for k, v := range o.sessionEnv(version) {
+		if err := os.Setenv(k, v); err != nil {
+			return 1, err
+		}
+	}
+
+	fmt.Printf("Starting %s with Odin %s. Exit the shell to return.\n", filepath.Base(shell), o.Colored(version, "green"))
+	return runPassthrough(exec.Command(shell))
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// configKey is a setting in Config that can be read and written with
+// `ovm config`. Key is its dotted TOML path, e.g. OLS.AutoRebuild.
+type configKey struct {
+	Key   string
+	value reflect.Value
+}
+
+// stateKeys are scalar Config fields that ovm manages itself, with how to
+// change them instead.
+var stateKeys = map[string]string{
+	"SchemaVersion": "it is updated automatically when config.toml is migrated",
+	"ActiveVersion": "use `ovm use` to change it",
+	"OLS.Active":    "use `ovm ols use` to change it",
+}
+
+// buildModes are the modes build_odin.sh accepts.
+var buildModes = []string{"debug", "release", "release-native", "nightly"}
+
+// keys lists every setting in c, in struct order. Only bool, int and string
+// fields are settings; lists, maps and timestamps are state.
+func (c *Config) keys() []configKey {
+	return collectKeys(reflect.ValueOf(c).Elem(), "")
+}
+
+func collectKeys(v reflect.Value, prefix string) []configKey {
+	var keys []configKey
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key := prefix + field.Name
+		switch field.Type.Kind() {
+		case reflect.Bool, reflect.Int, reflect.String:
+			if _, ok := stateKeys[key]; !ok {
+				keys = append(keys, configKey{Key: key, value: v.Field(i)})
+			}
+		case reflect.Struct:
+			if field.Type.PkgPath() == v.Type().PkgPath() {
+				keys = append(keys, collectKeys(v.Field(i), key+".")...)
+			}
+		}
+	}
+
+	return keys
+}
+
+// normalizeKey makes key matching ignore case, underscores and dashes, so
+// ols.auto_rebuild finds OLS.AutoRebuild.
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+func (c *Config) lookupKey(key string) (configKey, error) {
+	for _, k := range c.keys() {
+		if normalizeKey(k.Key) == normalizeKey(key) {
+			return k, nil
+		}
+	}
+
+	for k, hint := range stateKeys {
+		if normalizeKey(k) == normalizeKey(key) {
+			return configKey{}, fmt.Errorf("%s is managed by ovm, %s", k, hint)
+		}
+	}
+
+	return configKey{}, fmt.Errorf("unknown config key %q, run `ovm config list` to see the valid keys", key)
+}
+
+func (k configKey) String() string {
+	return fmt.Sprint(k.value.Interface())
+}
+
+// set parses value according to the type of the key and stores it.
+func (k configKey) set(value string) error {
+	switch k.value.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s expects true or false, got %q", k.Key, value)
+		}
+		k.value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s expects a whole number of 0 or more, got %q", k.Key, value)
+		}
+		k.value.SetInt(int64(n))
+	case reflect.String:
+		if k.Key == "BuildMode" && value != "" && !contains(buildModes, value) {
+			return fmt.Errorf("%s expects one of %s, got %q", k.Key, strings.Join(buildModes, ", "), value)
+		}
+		k.value.SetString(value)
+	}
+
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *OVM) ConfigGet(key string) error {
+	k, err := o.Config.lookupKey(key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(k)
+	return nil
+}
+
+func (o *OVM) ConfigSet(key, value string) error {
+	k, err := o.Config.lookupKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := k.set(value); err != nil {
+		return err
+	}
+
+	if err := o.Config.save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s = %s\n", o.Colored(k.Key, "green"), k)
+	return nil
+}
+
+// ConfigUnset resets key to its zero value, which is the default for every
+// setting.
+func (o *OVM) ConfigUnset(key string) error {
+	k, err := o.Config.lookupKey(key)
+	if err != nil {
+		return err
+	}
+
+	k.value.SetZero()
+	if err := o.Config.save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✔ Reset %s.\n", k.Key)
+	return nil
+}
+
+func (o *OVM) ConfigList() error {
+	keys := o.Config.keys()
+
+	if o.Output == OutputJSON {
+		values := make(map[string]any, len(keys))
+		for _, k := range keys {
+			values[k.Key] = k.value.Interface()
+		}
+		return printJSON(values)
+	}
+
+	for _, k := range keys {
+		if k.value.Kind() == reflect.String {
+			fmt.Printf("%s = %q\n", k.Key, k.value.String())
+		} else {
+			fmt.Printf("%s = %s\n", k.Key, k)
+		}
+	}
+
+	return nil
+}
+
+// ConfigEdit opens config.toml in $VISUAL or $EDITOR and checks that it
+// still loads once the editor exits.
+func (o *OVM) ConfigEdit() error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// the pre-edit copy is put back when the user gives up on an invalid
+	// edit, since a config that fails to load blocks every command,
+	// including this one
+	original, err := os.ReadFile(o.Config.basePath)
+	if err != nil {
+		return err
+	}
+
+	args := append(strings.Fields(editor), o.Config.basePath)
+	for {
+		if _, err := runPassthrough(exec.Command(args[0], args[1:]...)); err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(o.Config.basePath)
+		if err != nil {
+			return err
+		}
+
+		var edited Config
+		err = decodeConfig(o.Config.basePath, data, &edited)
+		if err == nil {
+			break
+		}
+
+		fmt.Fprintf(os.Stderr, "%s\nThe config is no longer valid. Would you like to edit it again? [y/n]\n", err)
+		if GetConfirmation() {
+			continue
+		}
+
+		if err := os.WriteFile(o.Config.basePath, original, 0755); err != nil {
+			return err
+		}
+		return fmt.Errorf("config is not valid, restored the previous version:\n%w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// shimNames are the binaries ovm links into ~/.ovm/bin. Each link points at
+// the ovm executable, which recognises the name it was invoked as and runs
+// the matching binary of the selected Odin version.
+var shimNames = []string{"odin", "ols", "odinfmt"}
+
+func IsShim(name string) bool {
+	for _, s := range shimNames {
+		if name == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RunShim replaces the current process with the real binary behind the shim
+// name, picked for the version selected in the current environment.
+func RunShim(name string, args []string) error {
+	o, err := initializeReadOnly()
+	if err != nil {
+		return err
+	}
+
+	sel := o.ResolveVersion()
+	if sel.Version == "" {
+		return fmt.Errorf("no Odin version selected, install one with `ovm i`")
+	}
+
+	path, err := o.resolveBinary(name, sel)
+	if err != nil {
+		return err
+	}
+
+	return execBinary(path, args)
+}
+
+// initializeReadOnly sets up ovm for the shims, which run on every compile.
+// Unlike Initialize it never creates the home directory or writes
+// config.toml, so it needs no lock; an older config is migrated in memory
+// only.
+func initializeReadOnly() (*OVM, error) {
+	layout, err := findHome()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &OVM{
+		baseDir:  layout.Base,
+		cacheDir: layout.Cache,
+		Output:   OutputPlain,
+	}
+	o.Config.basePath = layout.Config
+
+	if _, _, err := o.readConfig(); err != nil && !errors.Is(err, ErrNoConfig) {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return o, nil
+}
+
+// resolveBinary returns the absolute path of the odin, ols or odinfmt binary
+// belonging to the selected version.
+func (o *OVM) resolveBinary(name string, sel VersionSelection) (string, error) {
+	if err := o.checkInstalled(sel.Version); err != nil {
+		return "", fmt.Errorf("Odin %s (%s) is not installed, run `ovm install %s`", sel.Version, sel.Describe(), sel.Version)
+	}
+
+	var path string
+
+	switch name {
+	case "odin":
+		path = filepath.Join(o.baseDir, sel.Version, "odin"+exeSuffix)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("Odin %s (%s) is not installed, run `ovm install %s`", sel.Version, sel.Describe(), sel.Version)
+		}
+	case "ols", "odinfmt":
+		build, ok := o.olsBuildFor(sel.Version)
+		if !ok {
+			return "", ErrNoOLS
+		}
+
+		path = filepath.Join(o.baseDir, "ols", build.Dir(), name+exeSuffix)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%s is missing from OLS build %s, run `ovm ols install %s`", name, build.Dir(), build.Ref)
+		}
+	default:
+		return "", fmt.Errorf("unknown binary %q", name)
+	}
+
+	return path, nil
+}
+
+// installShims links the shims into ~/.ovm/bin. The OLS shims are only
+// installed once there is an OLS build for them to run.
+func (o *OVM) installShims() {
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatal("Could not locate the ovm executable", err)
+	}
+
+	if resolved, err := filepath.EvalSymlinks(self); err == nil {
+		self = resolved
+	}
+
+	for _, name := range shimNames {
+		if name != "odin" && len(o.Config.OLS.Builds) == 0 {
+			continue
+		}
+
+		o.createNamedSymlink(self, "bin", name+exeSuffix)
+	}
+}
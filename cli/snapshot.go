@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const defaultMasterRetention = 3
+
+// InstallTarget resolves a requested version to what should be installed.
+// "master" always means a fresh build of the branch rather than the alias
+// pointing at the newest snapshot, and is stored as a snapshot when master
+// snapshots are enabled.
+func (o *OVM) InstallTarget(requested string) TargetVersion {
+	if requested != "master" {
+		requested = o.ResolveAlias(requested)
+	}
+
+	return o.snapshotTarget(ValidateTargetVersion(requested))
+}
+
+// snapshotTarget renames a master build to master@<short-sha> when master
+// snapshots are enabled.
+func (o *OVM) snapshotTarget(tv TargetVersion) TargetVersion {
+	if o.Config.MasterSnapshots && tv.Tag == "master" && tv.Commit != "" {
+		tv.Tag = "master@" + shortSHA(tv.Commit)
+	}
+
+	return tv
+}
+
+// rotateMasterSnapshots points the master alias at the snapshot tag and
+// removes the oldest snapshots beyond the retention count, along with their
+// cached archives. The active
+// version and the snapshot master points to are always kept.
+func (o *OVM) rotateMasterSnapshots(tag string) error {
+	if err := o.Config.SetAlias("master", tag); err != nil {
+		return err
+	}
+	fmt.Printf("master -> %s\n", o.Colored(tag, "green"))
+
+	var snapshots []string
+	for _, v := range o.Config.InstalledVersions {
+		if strings.HasPrefix(v, "master@") {
+			snapshots = append(snapshots, v)
+		}
+	}
+
+	// newest first
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return o.Config.Installs[snapshots[i]].InstalledAt.After(o.Config.Installs[snapshots[j]].InstalledAt)
+	})
+
+	retention := o.Config.MasterRetention
+	if retention <= 0 {
+		retention = defaultMasterRetention
+	}
+
+	// snapshots pinned by an alias, like `ovm alias good master@<sha>`
+	pinned := make(map[string]bool)
+	for name := range o.Config.Aliases {
+		pinned[o.ResolveAlias(name)] = true
+	}
+
+	kept := 0
+	for _, snapshot := range snapshots {
+		if snapshot == tag || snapshot == o.Config.ActiveVersion || pinned[snapshot] || kept < retention {
+			kept++
+			continue
+		}
+
+		if o.Verbose {
+			fmt.Printf("Removing old master snapshot %s\n", snapshot)
+		}
+
+		if err := os.RemoveAll(filepath.Join(o.baseDir, snapshot)); err != nil {
+			return err
+		}
+
+		if key := sourceCacheKey(snapshot, o.Config.Installs[snapshot].Commit); key != "" {
+			archive := filepath.Join(o.cacheDir, key+".zip")
+			if err := os.Remove(archive); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+
+		if err := o.Config.RemoveInstalledVersion(snapshot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Update refreshes installs that track a moving ref. target is "master",
+// "latest", or "" for both. Nothing is rebuilt when the ref hasn't moved.
+func (o *OVM) Update(target string) error {
+	switch target {
+	case "master":
+		return o.updateMaster()
+	case "latest":
+		return o.updateLatest()
+	case "":
+		if err := o.updateMaster(); err != nil {
+			return err
+		}
+		return o.updateLatest()
+	default:
+		return fmt.Errorf("can't update %q, only master and latest are moving targets", target)
+	}
+}
+
+func (o *OVM) updateMaster() error {
+	// with snapshots, master is an alias for the newest one
+	current := o.ResolveAlias("master")
+	if !o.IsInstalled(current) {
+		fmt.Println("master is not installed. Run `ovm i master` to install it.")
+		return nil
+	}
+
+	head, err := GetGitHubCommit("odin-lang", "Odin", "master")
+	if err != nil {
+		return err
+	}
+
+	info := o.Config.Installs[current]
+	if info.Commit == head.SHA {
+		fmt.Printf("master is up to date (%s).\n", shortSHA(head.SHA))
+		return nil
+	}
+
+	if info.Commit == "" {
+		fmt.Printf("master: unknown -> %s\n", o.Colored(shortSHA(head.SHA), "green"))
+	} else {
+		fmt.Printf("master: %s -> %s\n", shortSHA(info.Commit), o.Colored(shortSHA(head.SHA), "green"))
+		o.printDiffstat(info.Commit, head.SHA)
+	}
+
+	target := o.snapshotTarget(TargetVersion{
+		Tag:    "master",
+		Commit: head.SHA,
+		Tracks: "master",
+		ZipUrl: fmt.Sprintf("https://github.com/odin-lang/Odin/archive/%s.zip", head.SHA),
+	})
+
+	if err := o.buildVersion(target); err != nil {
+		return err
+	}
+
+	if o.Config.ActiveVersion == current {
+		return o.setBin(target.Tag)
+	}
+
+	return nil
+}
+
+func (o *OVM) updateLatest() error {
+	var current string
+	for tag, info := range o.Config.Installs {
+		if info.Tracks == "latest" {
+			current = tag
+		}
+	}
+
+	if current == "" {
+		fmt.Println("No install tracks latest. Run `ovm i latest` to install the newest release.")
+		return nil
+	}
+
+	newest := ValidateTargetVersion("latest")
+	if newest.Tag == current {
+		fmt.Printf("latest is up to date (%s).\n", current)
+		return nil
+	}
+
+	fmt.Printf("latest: %s -> %s\n", current, o.Colored(newest.Tag, "green"))
+	o.printDiffstat(current, newest.Tag)
+
+	if err := o.buildVersion(newest); err != nil {
+		return err
+	}
+
+	// follow latest if it was the active version
+	if o.Config.ActiveVersion == current {
+		return o.setBin(newest.Tag)
+	}
+
+	return nil
+}
+
+// printDiffstat summarizes the changes between two commits or tags.
+func (o *OVM) printDiffstat(base, head string) {
+	comparison, err := GetGitHubComparison("odin-lang", "Odin", base, head)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't compare %s with %s: %v\n", base, head, err)
+		return
+	}
+
+	var additions, deletions int
+	for _, f := range comparison.Files {
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+
+	fmt.Printf("  %d commits, %d files changed, %s, %s\n", comparison.TotalCommits, len(comparison.Files),
+		o.Colored(fmt.Sprintf("+%d", additions), "green"), o.Colored(fmt.Sprintf("-%d", deletions), "red"))
+
+	if o.Verbose {
+		for _, f := range comparison.Files {
+			fmt.Printf("    %s | +%d -%d\n", f.Filename, f.Additions, f.Deletions)
+		}
+	}
+}
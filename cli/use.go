@@ -2,22 +2,26 @@ package cli
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 )
 
 func (o *OVM) Use(version string) error {
-	targetPath := filepath.Join(o.baseDir, version)
-	var err error
+	version = o.ResolveAlias(version)
+	if err := validateVersion(version); err != nil {
+		return err
+	}
 
-	if _, err = os.Stat(targetPath); errors.Is(err, os.ErrNotExist) {
+	var err error
+	if !o.IsInstalled(version) {
 		fmt.Printf("It looks like %s isn't installed. Would you like to install it? [y/n]\n", version)
 		if GetConfirmation() {
-			targetVersion := ValidateTargetVersion(version)
+			targetVersion := o.InstallTarget(version)
 			err = o.Install(targetVersion, false)
+			// installing master with snapshots enabled names it master@<sha>
+			version = targetVersion.Tag
 		} else {
 			return fmt.Errorf("Version %s is not installed", version)
 		}
@@ -30,13 +34,14 @@ func (o *OVM) Use(version string) error {
 }
 
 func (o *OVM) setBin(version string) error {
-	targetPath := filepath.Join(o.baseDir, version, "odin")
-	o.createSymlink(targetPath, "bin")
-
+	o.installShims()
 	o.linkCollections(version)
 
-	o.Config.ActiveVersion = version
-	if err := o.Config.save(); err != nil {
+	if err := o.Config.SetActiveVersion(version); err != nil {
+		return err
+	}
+
+	if err := o.syncOLS(); err != nil {
 		return err
 	}
 
@@ -45,6 +50,39 @@ func (o *OVM) setBin(version string) error {
 	return nil
 }
 
+// UsePrevious switches back to the version that was active before the
+// current one.
+func (o *OVM) UsePrevious() error {
+	previous, ok := o.Config.PreviousVersion()
+	if !ok {
+		return fmt.Errorf("no previous version to switch back to")
+	}
+
+	return o.Use(previous)
+}
+
+func (o *OVM) History() error {
+	if len(o.Config.History) == 0 {
+		fmt.Println("No version switches recorded yet.")
+		return nil
+	}
+
+	fmt.Println("Recent version switches (*active):")
+	for i, h := range o.Config.History {
+		marker := " "
+		if i == 0 && h.Version == o.Config.ActiveVersion {
+			marker = "*"
+		}
+		switchedAt := "unknown"
+		if !h.SwitchedAt.IsZero() {
+			switchedAt = h.SwitchedAt.Local().Format(time.DateTime)
+		}
+		fmt.Printf("%s %-19s  %s\n", marker, switchedAt, h.Version)
+	}
+
+	return nil
+}
+
 func GetConfirmation() bool {
 	reader := bufio.NewReader(os.Stdin)
 	text, _ := reader.ReadString('\n')
@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// treeName is the file in each install directory listing the SHA-256 of
+// every file in the install, in `sha256sum` format.
+const treeName = ".ovm-tree"
+
+var ErrVerifyFailed = errors.New("some installs don't match their recorded tree, run `ovm verify --repair` to fix them")
+
+// treeDiff lists how an install differs from its recorded tree.
+type treeDiff struct {
+	Modified []string
+	Missing  []string
+	Extra    []string
+}
+
+func (d treeDiff) clean() bool {
+	return len(d.Modified) == 0 && len(d.Missing) == 0 && len(d.Extra) == 0
+}
+
+// hashTree hashes every regular file under root, keyed by its slash
+// separated path relative to root. Symlinks, like the shared collection, and
+// the files ovm writes itself are skipped.
+func hashTree(root string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if rel == manifestName || rel == treeName {
+			return nil
+		}
+
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+
+	return files, err
+}
+
+// treeDigest combines the hashes of a tree into a single digest.
+func treeDigest(files map[string]string) string {
+	h := sha256.New()
+	for _, path := range sortedKeys(files) {
+		fmt.Fprintf(h, "%s  %s\n", files[path], path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// recordTree hashes the install of version, writes its tree file and
+// returns the tree digest.
+func (o *OVM) recordTree(version string) (string, error) {
+	installPath := filepath.Join(o.baseDir, version)
+	files, err := hashTree(installPath)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, path := range sortedKeys(files) {
+		fmt.Fprintf(&b, "%s  %s\n", files[path], path)
+	}
+
+	if err := os.WriteFile(filepath.Join(installPath, treeName), []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+
+	return treeDigest(files), nil
+}
+
+// readTree loads the tree file of version and checks it against the digest
+// recorded in its manifest.
+func (o *OVM) readTree(version string) (map[string]string, error) {
+	manifest, err := o.readManifest(version)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s has no install manifest, reinstall it to record one", version)
+		}
+		return nil, err
+	}
+
+	if manifest.TreeDigest == "" {
+		return nil, fmt.Errorf("%s has no recorded tree digest, reinstall it to record one", version)
+	}
+
+	f, err := os.Open(filepath.Join(o.baseDir, version, treeName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		sum, path, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			return nil, fmt.Errorf("malformed line in %s tree: %q", version, scanner.Text())
+		}
+		files[path] = sum
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if treeDigest(files) != manifest.TreeDigest {
+		return nil, fmt.Errorf("the tree file of %s doesn't match the digest in its manifest", version)
+	}
+
+	return files, nil
+}
+
+// diffTree compares the install of version with its recorded tree.
+func (o *OVM) diffTree(version string) (treeDiff, error) {
+	var diff treeDiff
+
+	recorded, err := o.readTree(version)
+	if err != nil {
+		return diff, err
+	}
+
+	current, err := hashTree(filepath.Join(o.baseDir, version))
+	if err != nil {
+		return diff, err
+	}
+
+	for _, path := range sortedKeys(recorded) {
+		sum, ok := current[path]
+		if !ok {
+			diff.Missing = append(diff.Missing, path)
+		} else if sum != recorded[path] {
+			diff.Modified = append(diff.Modified, path)
+		}
+	}
+
+	for _, path := range sortedKeys(current) {
+		if _, ok := recorded[path]; !ok {
+			diff.Extra = append(diff.Extra, path)
+		}
+	}
+
+	return diff, nil
+}
+
+// Verify checks installed versions against the trees recorded when they
+// were installed. With all set every installed version is checked, otherwise
+// version or the version selected for the current directory. With repair
+// set, damaged installs are restored from the archive cache.
+func (o *OVM) Verify(version string, all, repair bool) error {
+	var versions []string
+	if all {
+		versions = o.Config.InstalledVersions
+	} else {
+		if version == "" {
+			version = o.ResolveVersion().Version
+		}
+		versions = []string{o.ResolveAlias(version)}
+	}
+
+	failed := false
+	for _, v := range versions {
+		if !o.IsInstalled(v) {
+			return fmt.Errorf("version %s is not installed", v)
+		}
+
+		diff, err := o.diffTree(v)
+		if err != nil {
+			fmt.Printf("%s %s: %s\n", o.Colored("?", "yellow"), v, err)
+			failed = true
+			continue
+		}
+
+		if diff.clean() {
+			fmt.Printf("%s %s\n", o.Colored("✔", "green"), v)
+			continue
+		}
+
+		fmt.Printf("%s %s\n", o.Colored("✘", "red"), v)
+		printPaths("modified", diff.Modified)
+		printPaths("missing", diff.Missing)
+		printPaths("extra", diff.Extra)
+
+		if !repair {
+			failed = true
+			continue
+		}
+
+		if err := o.repairInstall(v, diff); err != nil {
+			fmt.Printf("  failed to repair %s: %s\n", v, err)
+			failed = true
+			continue
+		}
+
+		fmt.Printf("  %s repaired %s\n", o.Colored("✔", "green"), v)
+	}
+
+	if failed {
+		return ErrVerifyFailed
+	}
+
+	return nil
+}
+
+func printPaths(label string, paths []string) {
+	for _, path := range paths {
+		fmt.Printf("  %-9s %s\n", label+":", path)
+	}
+}
+
+// repairInstall restores the files in diff from the cached source archive
+// of version and removes extra files. Build outputs aren't in the archive,
+// so if any of them were damaged the install is rebuilt in place and its
+// tree is recorded again.
+func (o *OVM) repairInstall(version string, diff treeDiff) error {
+	manifest, err := o.readManifest(version)
+	if err != nil {
+		return err
+	}
+
+	commit := manifest.Commit
+	if commit == "" {
+		commit = o.Config.Installs[version].Commit
+	}
+
+	key := sourceCacheKey(version, commit)
+	archive := filepath.Join(o.cacheDir, key+".zip")
+	if _, err := os.Stat(archive); key == "" || err != nil {
+		return fmt.Errorf("no cached archive for %s, reinstall it with `ovm i %s`", version, version)
+	}
+
+	if sum, err := fileSHA256(archive); err != nil {
+		return err
+	} else if manifest.ArchiveSHA256 != "" && sum != manifest.ArchiveSHA256 {
+		return fmt.Errorf("cached archive %s doesn't match the checksum in the manifest", archive)
+	}
+
+	extracted, err := o.unzipSource(archive)
+	if err != nil {
+		return err
+	}
+	sourcePath := filepath.Join(o.baseDir, extracted)
+	defer os.RemoveAll(sourcePath)
+
+	installPath := filepath.Join(o.baseDir, version)
+	rebuild := false
+
+	for _, path := range append(diff.Modified, diff.Missing...) {
+		source := filepath.Join(sourcePath, filepath.FromSlash(path))
+		if _, err := os.Stat(source); err != nil {
+			// not part of the source, so it came from the build
+			rebuild = true
+			continue
+		}
+
+		if o.Verbose {
+			fmt.Printf("  restoring %s\n", path)
+		}
+
+		if err := copyFile(source, filepath.Join(installPath, filepath.FromSlash(path))); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range diff.Extra {
+		if o.Verbose {
+			fmt.Printf("  removing %s\n", path)
+		}
+
+		if err := os.Remove(filepath.Join(installPath, filepath.FromSlash(path))); err != nil {
+			return err
+		}
+	}
+
+	if !rebuild {
+		return nil
+	}
+
+	fmt.Printf("  Rebuilding %s...\n", o.Colored(version, "cyan"))
+	var buildArgs []string
+	if mode := o.Config.Installs[version].BuildMode; mode != "" {
+		buildArgs = []string{mode}
+	}
+
+	if err := o.buildSource(installPath, "build_odin.sh", version, buildArgs, nil); err != nil {
+		return err
+	}
+
+	manifest.TreeDigest, err = o.recordTree(version)
+	if err != nil {
+		return err
+	}
+
+	return o.writeManifest(*manifest)
+}
+
+// copyFile copies the file at source to dest, keeping its permissions.
+func copyFile(source, dest string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0775); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -11,6 +12,11 @@ import (
 
 type TargetVersion struct {
 	Tag, ZipUrl string
+	// Commit is the resolved commit for branch installs like master
+	Commit string
+	// Tracks is the moving ref ("master" or "latest") the version was requested as
+	Tracks      string
+	PublishedAt time.Time
 }
 
 func ValidateTargetVersion(input string) (tv TargetVersion) {
@@ -26,15 +32,39 @@ func ValidateTargetVersion(input string) (tv TargetVersion) {
 		rel := releases[0]
 		tv.Tag = rel.TagName
 		tv.ZipUrl = rel.ZipballURL
+		tv.PublishedAt = rel.PublishedAt
+		tv.Commit = releaseCommit(rel.TagName)
+		tv.Tracks = "latest"
 	case "master":
+		commit, err := GetGitHubCommit("odin-lang", "Odin", "master")
+		if err != nil {
+			log.Fatal("Failed to look up the master branch: ", err)
+		}
+
 		tv.Tag = "master"
-		tv.ZipUrl = "https://github.com/odin-lang/Odin/archive/refs/heads/master.zip"
+		tv.Tracks = "master"
+		tv.Commit = commit.SHA
+		tv.ZipUrl = fmt.Sprintf("https://github.com/odin-lang/Odin/archive/%s.zip", commit.SHA)
 	default:
+		if sha, ok := strings.CutPrefix(input, "master@"); ok {
+			commit, err := GetGitHubCommit("odin-lang", "Odin", sha)
+			if err != nil {
+				log.Fatal(ErrInvalidVersion, "err", err)
+			}
+
+			tv.Tag = "master@" + shortSHA(commit.SHA)
+			tv.Commit = commit.SHA
+			tv.ZipUrl = fmt.Sprintf("https://github.com/odin-lang/Odin/archive/%s.zip", commit.SHA)
+			return
+		}
+
 		for _, rel := range releases {
 			if rel.TagName == input {
 				log.Debug("Matched release", "rel", rel)
 				tv.Tag = rel.TagName
 				tv.ZipUrl = rel.ZipballURL
+				tv.PublishedAt = rel.PublishedAt
+				tv.Commit = releaseCommit(rel.TagName)
 				return
 			}
 		}
@@ -62,6 +92,80 @@ func GetGitHubReleases(owner, repo string) ([]GithubRelease, error) {
 	return releases, nil
 }
 
+// GetGitHubCommit looks up the commit that ref (a branch, tag or SHA)
+// currently points to.
+func GetGitHubCommit(owner, repo, ref string) (GithubCommit, error) {
+	var commit GithubCommit
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s", owner, repo, ref)
+	resp, err := http.Get(url)
+	if err != nil {
+		return commit, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return commit, fmt.Errorf("failed to look up %s/%s@%s: %s", owner, repo, ref, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&commit)
+	return commit, err
+}
+
+// releaseCommit resolves a release tag to the commit it points at. A failed
+// lookup only costs the install its recorded commit, so it isn't fatal.
+func releaseCommit(tag string) string {
+	commit, err := GetGitHubCommit("odin-lang", "Odin", tag)
+	if err != nil {
+		log.Warn("Failed to resolve the commit of the release", "tag", tag, "err", err)
+		return ""
+	}
+
+	return commit.SHA
+}
+
+// GetGitHubComparison compares two commits, branches or tags.
+func GetGitHubComparison(owner, repo, base, head string) (GithubComparison, error) {
+	var comparison GithubComparison
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/compare/%s...%s", owner, repo, base, head)
+	resp, err := http.Get(url)
+	if err != nil {
+		return comparison, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return comparison, fmt.Errorf("failed to compare %s...%s: %s", base, head, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&comparison)
+	return comparison, err
+}
+
+type GithubComparison struct {
+	Status       string `json:"status"`
+	AheadBy      int    `json:"ahead_by"`
+	BehindBy     int    `json:"behind_by"`
+	TotalCommits int    `json:"total_commits"`
+	Files        []struct {
+		Filename  string `json:"filename"`
+		Additions int    `json:"additions"`
+		Deletions int    `json:"deletions"`
+	} `json:"files"`
+}
+
+type GithubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message   string `json:"message"`
+		Committer struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
 type GithubRelease struct {
 	URL       string `json:"url"`
 	AssetsURL string `json:"assets_url"`
@@ -135,6 +239,32 @@ type GithubRelease struct {
 	Body       string `json:"body"`
 }
 
+// validateVersion rejects version names that can't be an install, like
+// ".." or names with path separators, which would point outside the ovm
+// home when joined to it. Versions from project files, OVM_VERSION and
+// aliases are checked with it before they are used.
+func validateVersion(version string) error {
+	if version == "" || version == "." || version == ".." || strings.ContainsAny(version, `/\`) {
+		return fmt.Errorf("invalid version name %q", version)
+	}
+
+	return nil
+}
+
+// checkInstalled returns an error unless version is a valid name recorded as
+// installed.
+func (o *OVM) checkInstalled(version string) error {
+	if err := validateVersion(version); err != nil {
+		return err
+	}
+
+	if !o.IsInstalled(version) {
+		return fmt.Errorf("version %s is not installed", version)
+	}
+
+	return nil
+}
+
 func (o *OVM) IsInstalled(version string) bool {
 	for _, v := range o.Config.InstalledVersions {
 		if v == version {
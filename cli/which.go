@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WhichRecord is the structured output of `ovm which`.
+type WhichRecord struct {
+	Binary     string `json:"binary"`
+	Path       string `json:"path"`
+	Version    string `json:"version"`
+	Source     string `json:"source"`
+	SourcePath string `json:"source_path"`
+	Alias      string `json:"alias"`
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// Current prints the version selected for the current directory and where
+// the selection came from.
+func (o *OVM) Current() error {
+	sel := o.ResolveVersion()
+
+	if o.Output != OutputPlain {
+		rec := CurrentRecord{
+			VersionRecord: o.versionRecord(sel.Version, sel.Version),
+			Source:        sel.Source,
+			SourcePath:    sel.Path,
+			Alias:         sel.Alias,
+		}
+
+		if o.Output == OutputJSON {
+			return printJSON(rec)
+		}
+
+		printTable([]string{"TAG", "INSTALLED", "SOURCE", "SOURCE PATH", "ALIAS", "PATH"}, [][]string{{
+			orDash(rec.Tag), formatBool(rec.Installed), rec.Source, orDash(rec.SourcePath),
+			orDash(rec.Alias), orDash(rec.InstallPath),
+		}})
+		return nil
+	}
+
+	if sel.Version == "" {
+		fmt.Println("No Odin version is active. Install one with `ovm i`.")
+		return nil
+	}
+
+	fmt.Printf("%s (%s)\n", o.Colored(sel.Version, "green"), sel.Describe())
+
+	if !o.IsInstalled(sel.Version) {
+		fmt.Printf("%s is not installed. Run `ovm install %s` to install it.\n", sel.Version, sel.Version)
+	}
+
+	return nil
+}
+
+// Which prints the absolute path of the binary the odin, ols or odinfmt shim
+// runs in the current directory.
+func (o *OVM) Which(name string) error {
+	if !IsShim(name) {
+		return fmt.Errorf("unknown binary %q, expected odin, ols or odinfmt", name)
+	}
+
+	sel := o.ResolveVersion()
+	if sel.Version == "" {
+		return fmt.Errorf("no Odin version selected, install one with `ovm i`")
+	}
+
+	path, err := o.resolveBinary(name, sel)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == OutputJSON {
+		return printJSON(WhichRecord{
+			Binary:     name,
+			Path:       path,
+			Version:    sel.Version,
+			Source:     sel.Source,
+			SourcePath: sel.Path,
+			Alias:      sel.Alias,
+		})
+	}
+
+	fmt.Println(path)
+	return nil
+}
@@ -7,8 +7,8 @@ import (
 	"os"
 	"ovm/cli"
 	"ovm/cli/meta"
-
-	// "strings"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	flag "github.com/spf13/pflag"
@@ -25,18 +25,68 @@ func main() {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	// ~/.ovm/bin/odin, ols and odinfmt are links to ovm itself
+	if name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"); cli.IsShim(name) {
+		if err := cli.RunShim(name, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	if len(args) == 0 {
 		printHelp()
 		os.Exit(0)
 	}
 
+	// fmt passes every argument through to odinfmt, so it is dispatched
+	// before ovm's own flags are parsed
+	if args[0] == "fmt" {
+		ovm := cli.Initialize(false)
+		code, err := ovm.Format(args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(code)
+	}
+
 	installFlagSet := flag.NewFlagSet("install", flag.ExitOnError)
 	installLsp := flag.BoolP("lsp", "l", false, "Specify if OLS should be installed with Odin")
 	installFlagSet.AddFlag(flag.ShorthandLookup("l"))
 
+	olsConfigFlagSet := flag.NewFlagSet("ols config", flag.ExitOnError)
+	olsConfigGlobal := flag.BoolP("global", "g", false, "Write the ols.json shared by every OLS build")
+	olsConfigFlagSet.AddFlag(flag.ShorthandLookup("g"))
+	olsConfigProject := flag.BoolP("project", "p", false, "Write ols.json to the current directory (the default)")
+	olsConfigFlagSet.AddFlag(flag.ShorthandLookup("p"))
+
+	envFlagSet := flag.NewFlagSet("env", flag.ExitOnError)
+	envShell := flag.StringP("shell", "s", "", "Shell syntax to print: bash, zsh, fish or posix")
+	envFlagSet.AddFlag(flag.ShorthandLookup("s"))
+	envHook := flag.Bool("hook", false, "Print the commands run by the ovm init shell hook")
+	envFlagSet.AddFlag(flag.Lookup("hook"))
+	envInstallMissing := flag.Bool("install-missing", false, "Offer to install missing project versions from the shell hook")
+	envFlagSet.AddFlag(flag.Lookup("install-missing"))
+
 	lsFlagSet := flag.NewFlagSet("ls", flag.ExitOnError)
 	lsRemote := flag.BoolP("remote", "r", false, "List Odin versions available for download")
 	lsFlagSet.AddFlag(flag.ShorthandLookup("r"))
+	// --since is a date for `ls -r` and a version for `notes`
+	since := flag.String("since", "", "Only list releases published since a month (YYYY-MM) or date, or with notes, newer than a version")
+	lsFlagSet.AddFlag(flag.Lookup("since"))
+	lsLimit := flag.Int("limit", 0, "List at most this many releases")
+	lsFlagSet.AddFlag(flag.Lookup("limit"))
+	lsPrerelease := flag.Bool("include-prerelease", false, "Include prereleases in the remote listing")
+	lsFlagSet.AddFlag(flag.Lookup("include-prerelease"))
+	lsNotInstalled := flag.Bool("not-installed", false, "Only list releases that aren't installed")
+	lsFlagSet.AddFlag(flag.Lookup("not-installed"))
+
+	outputMode := flag.String("output", cli.OutputPlain, "Output format for listing commands: json, table or plain")
+	jsonMode := flag.Bool("json", false, "Shorthand for --output json")
+
+	allVersions := flag.Bool("all", false, "Apply to every version the command supports")
+	verifyRepair := flag.Bool("repair", false, "Restore damaged installs from the archive cache")
+
+	waitForLock := flag.Bool("wait", false, "Wait for other ovm processes to finish instead of failing")
 
 	verboseMode := flag.BoolP("verbose", "v", false, "Show extra output during operations")
 	flag.Parse()
@@ -44,21 +94,51 @@ func main() {
 	ovm := cli.Initialize(*verboseMode)
 	args = flag.Args()
 
+	if *jsonMode {
+		*outputMode = cli.OutputJSON
+	}
+	if err := cli.ValidateOutput(*outputMode); err != nil {
+		log.Fatal(err)
+	}
+	ovm.Output = *outputMode
+	ovm.WaitForLock = *waitForLock
+
+	// only flags were given
+	if len(args) == 0 {
+		return
+	}
+
+	// commands whose output is read by scripts or shells skip the notice
+	switch args[0] {
+	case "outdated", "env", "init", "exec", "shell", "current", "which", "version", "help":
+	default:
+		defer ovm.UpdateNotice()
+	}
+
+	if action := lockAction(args, *olsConfigGlobal, *verifyRepair); action != "" {
+		if err := ovm.Lock(action); err != nil {
+			log.Fatal(err)
+		}
+		defer ovm.Unlock()
+	}
+
 	for i, arg := range args {
 		switch arg {
 
 		case "install", "i":
 			installFlagSet.Parse(args[i+1:])
 
-			// default to "latest" if no version given
+			// default to the project version, or "latest" if there is none
 			var requestedVersion string
 			if len(args) > i+1 {
 				requestedVersion = installFlagSet.Arg(0)
+			} else if projectVersion := cli.ProjectVersion(); projectVersion != "" {
+				requestedVersion = projectVersion
 			} else {
 				requestedVersion = "latest"
 			}
 
-			targetVersion := cli.ValidateTargetVersion(requestedVersion)
+			targetVersion := ovm.InstallTarget(requestedVersion)
 
 			if ovm.Verbose {
 				var outVer string
@@ -77,20 +157,248 @@ func main() {
 			return
 
 		case "use", "switch":
-			if len(args) > i+1 {
+			if len(args) > i+1 && args[i+1] == "-" {
+				if err := ovm.UsePrevious(); err != nil {
+					log.Fatal(err)
+				}
+			} else if len(args) > i+1 {
 				version := args[i+1]
 				if err := ovm.Use(version); err != nil {
 					log.Fatal(err)
 				}
+			} else if err := ovm.UseProjectVersion(); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "exec":
+			if len(args) <= i+1 {
+				log.Fatal("missing version. Usage: `ovm exec <version> -- <command>`")
+			}
+
+			command := args[i+2:]
+			if dash := flag.CommandLine.ArgsLenAtDash(); dash > i+1 {
+				command = args[dash:]
+			}
+
+			code, err := ovm.Exec(args[i+1], command)
+			if err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(code)
+
+		case "env":
+			envFlagSet.Parse(args[i+1:])
+			if *envHook {
+				if err := ovm.HookEnv(*envShell, *envInstallMissing); err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
+
+			if len(args) <= i+1 {
+				log.Fatal("missing version. Usage: `ovm env <version> [--shell bash|zsh|fish|posix]`")
+			}
+
+			if err := ovm.Env(args[i+1], *envShell); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "shell":
+			if len(args) <= i+1 {
+				log.Fatal("missing version. Usage: `ovm shell <version>`")
+			}
+
+			code, err := ovm.Shell(args[i+1])
+			if err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(code)
+
+		case "init":
+			if len(args) <= i+1 {
+				log.Fatal("missing shell. Usage: `ovm init bash|zsh|fish`")
+			}
+
+			if err := ovm.Init(args[i+1], *envInstallMissing); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "history":
+			if err := ovm.History(); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "alias":
+			var err error
+			switch {
+			case len(args) <= i+1 || args[i+1] == "ls" || args[i+1] == "list":
+				err = ovm.ListAliases()
+			case len(args) == i+2:
+				log.Fatal("missing version. Usage: `ovm alias <name> <version>`")
+			default:
+				err = ovm.SetAlias(args[i+1], args[i+2])
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "unalias":
+			if len(args) <= i+1 {
+				log.Fatal("missing alias. Usage: `ovm unalias <name>`")
+			}
+
+			if err := ovm.RemoveAlias(args[i+1]); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "current":
+			if err := ovm.Current(); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "info":
+			var version string
+			if len(args) > i+1 {
+				version = args[i+1]
+			}
+
+			if err := ovm.Info(version); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "config":
+			if len(args) <= i+1 {
+				log.Fatal("missing config command. Usage: `ovm config get|set|unset|list|edit`")
+			}
+
+			var err error
+			switch sub := args[i+1]; sub {
+			case "get", "unset":
+				if len(args) <= i+2 {
+					log.Fatalf("missing config key. Usage: `ovm config %s <key>`", sub)
+				}
+				if sub == "get" {
+					err = ovm.ConfigGet(args[i+2])
+				} else {
+					err = ovm.ConfigUnset(args[i+2])
+				}
+			case "set":
+				if len(args) <= i+3 {
+					log.Fatal("missing config key or value. Usage: `ovm config set <key> <value>`")
+				}
+				err = ovm.ConfigSet(args[i+2], args[i+3])
+			case "list", "ls":
+				err = ovm.ConfigList()
+			case "edit":
+				err = ovm.ConfigEdit()
+			default:
+				log.Fatalf("invalid config command %q. Have a look at `ovm help` for usage.\n", sub)
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "relocate":
+			if len(args) <= i+1 {
+				log.Fatal("missing directory. Usage: `ovm relocate <newdir>`")
+			}
+
+			if err := ovm.Relocate(args[i+1]); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "verify":
+			var version string
+			if len(args) > i+1 {
+				version = args[i+1]
+			}
+
+			if err := ovm.Verify(version, *allVersions, *verifyRepair); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "update":
+			var target string
+			if len(args) > i+1 && !*allVersions {
+				target = args[i+1]
+			}
+
+			if err := ovm.Update(target); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "outdated":
+			if err := ovm.Outdated(); err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "notes":
+			var err error
+			if *since != "" {
+				err = ovm.NotesSince(*since)
+			} else if len(args) > i+1 {
+				err = ovm.Notes(args[i+1])
+			} else {
+				err = ovm.Notes("")
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+
+		case "which":
+			if len(args) <= i+1 {
+				log.Fatal("missing binary. Usage: `ovm which odin|ols|odinfmt`")
+			}
+
+			if err := ovm.Which(args[i+1]); err != nil {
+				log.Fatal(err)
 			}
 			return
 
 		case "ls", "list":
 			lsFlagSet.Parse(args[i+1:])
-			err := ovm.ListVersions(*lsRemote)
-			if err != nil {
+			if !*lsRemote {
+				if err := ovm.ListVersions(false); err != nil {
+					log.Warn(err)
+				}
+				return
+			}
+
+			filter := cli.RemoteFilter{
+				Limit:             *lsLimit,
+				IncludePrerelease: *lsPrerelease,
+				NotInstalled:      *lsNotInstalled,
+			}
+
+			if *since != "" {
+				sinceDate, err := cli.ParseSince(*since)
+				if err != nil {
+					log.Fatal(err)
+				}
+				filter.Since = sinceDate
+			}
+
+			if err := ovm.ListRemoteVersions(filter); err != nil {
 				log.Warn(err)
 			}
+			return
 
 		case "remove", "rm":
 			if len(args) > i+1 {
@@ -100,6 +408,45 @@ func main() {
 				}
 			}
 
+		case "ols":
+			if len(args) <= i+1 {
+				log.Fatal("missing ols command. Have a look at `ovm help` for usage.")
+			}
+
+			var err error
+			switch args[i+1] {
+			case "install", "i":
+				ref := "master"
+				if len(args) > i+2 {
+					ref = args[i+2]
+				}
+				err = ovm.OLSInstall(ref)
+			case "update":
+				err = ovm.OLSUpdate()
+			case "remove", "rm":
+				err = ovm.OLSRemove()
+			case "status":
+				err = ovm.OLSStatus()
+			case "config":
+				olsConfigFlagSet.Parse(args[i+2:])
+				if *olsConfigGlobal && *olsConfigProject {
+					log.Fatal("--global and --project can't be used together")
+				}
+				err = ovm.OLSConfigure(*olsConfigGlobal)
+			case "use":
+				if len(args) <= i+2 {
+					log.Fatal("missing OLS ref. Usage: `ovm ols use <ref>`")
+				}
+				err = ovm.OLSUse(args[i+2])
+			default:
+				log.Fatalf("invalid ols command %q. Have a look at `ovm help` for usage.\n", args[i+1])
+			}
+
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+
 		case "upgrade", "u":
 			if err := ovm.Upgrade(); err != nil {
 				log.Fatal(err)
@@ -133,6 +480,78 @@ func main() {
 
 }
 
+// lockAction describes what a command is about to change in the ovm home
+// for other ovm processes. Commands that only read it return "" and run
+// without taking the lock.
+func lockAction(args []string, olsGlobal, repair bool) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	arg := func(i int) string {
+		if len(args) > i {
+			return args[i]
+		}
+		return ""
+	}
+
+	switch args[0] {
+	case "install", "i":
+		if version := arg(1); version != "" {
+			return "installing " + version
+		}
+		return "installing Odin"
+	case "use", "switch":
+		if version := arg(1); version != "" {
+			return "switching to " + version
+		}
+		return "switching versions"
+	case "remove", "rm":
+		return "removing " + arg(1)
+	case "update":
+		if target := arg(1); target != "" {
+			return "updating " + target
+		}
+		return "updating master and latest"
+	case "upgrade", "u":
+		return "upgrading ovm"
+	case "alias":
+		if arg(2) != "" {
+			return "updating aliases"
+		}
+	case "unalias":
+		return "updating aliases"
+	case "colors":
+		return "changing settings"
+	case "config":
+		switch arg(1) {
+		case "set", "unset", "edit":
+			return "changing settings"
+		}
+	case "ols":
+		switch arg(1) {
+		case "install", "i", "update":
+			return "building OLS"
+		case "use":
+			return "switching OLS"
+		case "remove", "rm":
+			return "removing OLS"
+		case "config":
+			if olsGlobal {
+				return "configuring OLS"
+			}
+		}
+	case "relocate":
+		return "relocating ovm"
+	case "verify":
+		if repair {
+			return "repairing installs"
+		}
+	}
+
+	return ""
+}
+
 func printHelp() {
 	helpTemplate, err := template.New("help").Parse(helpText)
 	if err != nil {